@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var flagFPS = flag.Int("fps", 10, "frames per second drawn by the animate, tui and export commands")
+
+// frameInterval converts the -fps flag into a redraw interval.
+func frameInterval() time.Duration {
+	if *flagFPS <= 0 {
+		return time.Second
+	}
+	return time.Second / time.Duration(*flagFPS)
+}
+
+// animation is a spectator for plain terminals. It redraws, in place, the
+// table as a compact line of philosophers (E, T, W or x) each followed by
+// their right-hand tray ('|' if the stick is in it), wrapped to the
+// terminal's width. The focused philosopher is drawn in lower case.
+// When verbose, the latest event of interest is shown below the table.
+type animation struct {
+	keyboard
+	view     *tableView
+	chef     *chef
+	interval time.Duration
+	latest   string
+	focusCh  chan int
+	done     chan struct{}
+	// drawnLines is how many lines the last frame took, so the next can overwrite it.
+	drawnLines int
+}
+
+func newAnimation(numPhilosophers int, c *chef, interval time.Duration, kb keyboard) *animation {
+	return &animation{
+		keyboard: kb,
+		view:     newTableView(numPhilosophers),
+		chef:     c,
+		interval: interval,
+		focusCh:  make(chan int),
+		done:     make(chan struct{}),
+	}
+}
+
+func (a *animation) ownsTerminal() bool { return true }
+
+func (a *animation) focus(id int) {
+	select {
+	case a.focusCh <- id:
+	case <-a.done:
+	}
+}
+
+func (a *animation) watch(events <-chan event) {
+	defer close(a.done)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case a.focused = <-a.focusCh:
+		case k := <-a.keys:
+			a.press(k, a.view)
+		case e, ok := <-events:
+			if !ok {
+				a.draw()
+				return
+			}
+			a.view.apply(e)
+			if a.shows(e) {
+				a.latest = fmt.Sprintf("p%d %s", e.id, e.msg)
+			}
+		case <-ticker.C:
+			a.draw()
+		}
+	}
+}
+
+func (a *animation) draw() {
+	cols, _ := terminalSize()
+	perLine := cols / 2
+	if perLine < 1 {
+		perLine = 1
+	}
+	var b strings.Builder
+	if a.drawnLines > 0 {
+		// Back to the start of the previous frame.
+		fmt.Fprintf(&b, "\r\x1b[%dA", a.drawnLines)
+	}
+	lines := 0
+	for i, state := range a.view.states {
+		g := state.glyph()
+		if i == a.focused {
+			g = unicode.ToLower(g)
+		}
+		b.WriteRune(g)
+		if a.view.owner[i] < 0 {
+			b.WriteByte('|')
+		} else {
+			b.WriteByte(' ')
+		}
+		if (i+1)%perLine == 0 || i == len(a.view.states)-1 {
+			b.WriteString("\n")
+			lines++
+		}
+	}
+	fmt.Fprintf(&b, "%s\x1b[K\n", tr(msgAnimateStatus,
+		a.chef.servingsLeft(), a.view.totalEaten,
+		a.view.count(stateEating), a.view.count(stateWaiting)))
+	a.drawnLines = lines + 1
+	if a.verbose {
+		fmt.Fprintf(&b, "%s\x1b[K\n", a.latest)
+		a.drawnLines++
+	}
+	// Clear anything left below by a longer, earlier frame.
+	b.WriteString("\x1b[J")
+	fmt.Print(b.String())
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// build describes the binary, so results can be traced to it.
+type build struct {
+	Module   string `json:"module,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Revision string `json:"revision,omitempty"`
+	Modified bool   `json:"modified,omitempty"`
+	Time     string `json:"time,omitempty"`
+	Go       string `json:"go"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Tags     string `json:"tags,omitempty"`
+	// Known is false if the binary carries no build information.
+	Known bool `json:"known"`
+}
+
+func readBuild() build {
+	b := build{Go: runtime.Version(), OS: runtime.GOOS, Arch: runtime.GOARCH}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	settings := map[string]string{}
+	for _, s := range bi.Settings {
+		settings[s.Key] = s.Value
+	}
+	b.Known = true
+	b.Module = bi.Main.Path
+	b.Version = bi.Main.Version
+	b.Revision = settings["vcs.revision"]
+	b.Modified = settings["vcs.modified"] == "true"
+	b.Time = settings["vcs.time"]
+	b.Go = bi.GoVersion
+	b.Tags = settings["-tags"]
+	return b
+}
+
+// buildInfo returns lines describing the binary, so results can be traced to a build.
+func buildInfo() []string {
+	b := readBuild()
+	if !b.Known {
+		return []string{tr(msgBuildGo, b.Go, b.OS, b.Arch), tr(msgBuildUnknown)}
+	}
+	revision := b.Revision
+	if revision == "" {
+		revision = "?"
+	}
+	if b.Modified {
+		revision += tr(msgBuildModified)
+	}
+	tags := b.Tags
+	if tags == "" {
+		tags = "-"
+	}
+	return []string{
+		tr(msgBuildModule, b.Module, b.Version),
+		strings.TrimSpace(tr(msgBuildRevision, revision, b.Time)),
+		tr(msgBuildGo, b.Go, b.OS, b.Arch),
+		tr(msgBuildTags, tags),
+	}
+}
+
+func printBuildInfo() {
+	for _, line := range buildInfo() {
+		fmt.Println(line)
+	}
+}
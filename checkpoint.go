@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+var (
+	flagCheckpoint = flag.String("checkpoint", "",
+		"file to save the dinner in, to -resume later, on SIGTERM or the checkpoint command")
+	flagResume = flag.String("resume", "", "checkpoint file to resume a dinner from")
+)
+
+// checkpoint is a dinner stopped at a safe point, where no philosopher
+// holds a stick, so every stick is in its own tray and nothing but the
+// counters needs saving to put the table back as it was.
+type checkpoint struct {
+	Time time.Time `json:"time"`
+	// Elapsed is how long the dinner had run, counting earlier resumptions.
+	Elapsed      time.Duration           `json:"elapsed"`
+	Config       runConfig               `json:"config"`
+	ServingsLeft int                     `json:"servingsLeft"`
+	Speed        float64                 `json:"speed"`
+	Philosophers []philosopherCheckpoint `json:"philosophers"`
+	Sticks       []jsonStick             `json:"sticks"`
+	// ScriptRun counts the commands of a -scenario's phases already run.
+	ScriptRun int `json:"scriptRun,omitempty"`
+}
+
+type philosopherCheckpoint struct {
+	ID        int           `json:"id"`
+	Waits     int           `json:"waits"`
+	Eaten     int           `json:"eaten"`
+	Wakeups   int           `json:"wakeups"`
+	WakeupSum time.Duration `json:"wakeupSum"`
+	WakeupMax time.Duration `json:"wakeupMax"`
+	// LongestWait is the longest wait for sticks.
+	LongestWait time.Duration `json:"longestWait"`
+	AskedToGo   bool          `json:"askedToGo"`
+}
+
+// takeCheckpoint saves the counters of a dinner that must be paused.
+func (c *controller) takeCheckpoint() *checkpoint {
+	cp := &checkpoint{
+		ServingsLeft: c.chef.servingsLeft(),
+		Speed:        c.speed,
+		ScriptRun:    c.scriptRun,
+		Philosophers: make([]philosopherCheckpoint, len(c.dt)),
+		Sticks:       make([]jsonStick, len(c.dt)),
+	}
+	for i := range c.dt {
+		p := &c.dt[i].diner
+		cp.Philosophers[i] = philosopherCheckpoint{
+			ID:          p.id,
+			Waits:       p.hadToWaitCount,
+			Eaten:       p.servingsEatenCount,
+			Wakeups:     p.wakeupCount,
+			WakeupSum:   p.wakeupLatencySum,
+			WakeupMax:   p.wakeupLatencyMax,
+			LongestWait: p.longestWait,
+			AskedToGo:   c.killed[i],
+		}
+		s := &c.dt[i].stick
+		cp.Sticks[i] = jsonStick{ID: s.id, Grabs: s.countGrab, Eats: s.countEat}
+	}
+	return cp
+}
+
+// restore puts the counters of a checkpoint back on a new table, and
+// asks anyone who had been asked to leave to leave again.
+func (dt diningTable) restore(cp *checkpoint) error {
+	if len(cp.Philosophers) != len(dt) || len(cp.Sticks) != len(dt) {
+		return fmt.Errorf("the checkpoint is of %d philosophers, not %d", len(cp.Philosophers), len(dt))
+	}
+	for i, pc := range cp.Philosophers {
+		p := &dt[i].diner
+		p.hadToWaitCount = pc.Waits
+		p.servingsEatenCount = pc.Eaten
+		p.wakeupCount = pc.Wakeups
+		p.wakeupLatencySum = pc.WakeupSum
+		p.wakeupLatencyMax = pc.WakeupMax
+		p.longestWait = pc.LongestWait
+		if pc.AskedToGo {
+			close(p.kill)
+		}
+		dt[i].stick.countGrab = cp.Sticks[i].Grabs
+		dt[i].stick.countEat = cp.Sticks[i].Eats
+	}
+	return nil
+}
+
+func saveCheckpoint(name string, cp *checkpoint) error {
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(b, '\n'), 0o644)
+}
+
+func loadCheckpoint(name string) (*checkpoint, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	cp := &checkpoint{}
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, fmt.Errorf("reading checkpoint %s: %v", name, err)
+	}
+	if cp.Speed <= 0 {
+		cp.Speed = 1
+	}
+	return cp, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// completionShells are the shells the completion command writes scripts for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completionFiles marks flags whose value is a file name.
+var completionFiles = map[string]bool{
+	"formatFile": true,
+	"eventlog":   true,
+	"history":    true,
+	"checkpoint": true,
+	"resume":     true,
+	"scenario":   true,
+}
+
+// flagChoices returns the values a flag can take, if it's limited to a few.
+func flagChoices(name string) []string {
+	switch name {
+	case "output":
+		return []string{outputAll, outputCoalesce, outputAuto}
+	case "notify":
+		return []string{notifyNone, notifyBell, notifyDesktop}
+	case "strategy":
+		return strategyNames()
+	case "ids":
+		return []string{idLane, idTag, idColor}
+	case "lang":
+		var langs []string
+		for lang := range catalogs {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		return langs
+	}
+	return nil
+}
+
+// completionFlag is what a completion script needs to know about a flag.
+type completionFlag struct {
+	name    string
+	usage   string
+	isBool  bool
+	choices []string
+	isFile  bool
+}
+
+func completionFlags() []completionFlag {
+	var flags []completionFlag
+	flag.VisitAll(func(f *flag.Flag) {
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			name:    f.Name,
+			usage:   f.Usage,
+			isBool:  ok && b.IsBoolFlag(),
+			choices: flagChoices(f.Name),
+			isFile:  completionFiles[f.Name],
+		})
+	})
+	return flags
+}
+
+func subcommandNames() []string {
+	names := make([]string, len(subcommands))
+	for i, c := range subcommands {
+		names[i] = c.name
+	}
+	return names
+}
+
+// writeCompletion writes a completion script for shell, covering the
+// subcommands, the flags, and the values of flags that take one of a few.
+func writeCompletion(w io.Writer, shell string) error {
+	prog := filepath.Base(os.Args[0])
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, prog)
+	case "zsh":
+		writeZshCompletion(w, prog)
+	case "fish":
+		writeFishCompletion(w, prog)
+	default:
+		return fmt.Errorf("completion needs a shell: one of %s", strings.Join(completionShells, ", "))
+	}
+	return nil
+}
+
+// shellIdent turns a program name into something usable in a function name.
+func shellIdent(prog string) string {
+	return regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(prog, "_")
+}
+
+func writeBashCompletion(w io.Writer, prog string) {
+	fn := "_" + shellIdent(prog) + "_complete"
+	var names []string
+	fmt.Fprintf(w, "# bash completion for %s\n", prog)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(w, "    case \"$prev\" in\n")
+	for _, f := range completionFlags() {
+		names = append(names, "-"+f.name)
+		switch {
+		case f.isBool:
+			continue
+		case f.choices != nil:
+			fmt.Fprintf(w, "    -%s|--%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return;;\n",
+				f.name, f.name, strings.Join(f.choices, " "))
+		case f.isFile:
+			fmt.Fprintf(w, "    -%s|--%s) COMPREPLY=($(compgen -f -- \"$cur\")); return;;\n", f.name, f.name)
+		default:
+			fmt.Fprintf(w, "    -%s|--%s) COMPREPLY=(); return;;\n", f.name, f.name)
+		}
+	}
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "    if [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then\n")
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W %q -- \"$cur\")); return\n", strings.Join(subcommandNames(), " "))
+	fmt.Fprintf(w, "    fi\n")
+	fmt.Fprintf(w, "    if [[ ${COMP_WORDS[1]} == completion && $cur != -* ]]; then\n")
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W %q -- \"$cur\")); return\n", strings.Join(completionShells, " "))
+	fmt.Fprintf(w, "    fi\n")
+	fmt.Fprintf(w, "    COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -F %s %s\n", fn, prog)
+}
+
+// zshQuote escapes s for use inside a single-quoted _arguments spec.
+func zshQuote(s string) string {
+	return strings.NewReplacer(`'`, `'\''`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+func writeZshCompletion(w io.Writer, prog string) {
+	fn := "_" + shellIdent(prog)
+	fmt.Fprintf(w, "#compdef %s\n", prog)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "    local -a opts cmds\n")
+	fmt.Fprintf(w, "    opts=(\n")
+	for _, f := range completionFlags() {
+		spec := fmt.Sprintf("-%s[%s]", f.name, zshQuote(f.usage))
+		switch {
+		case f.isBool:
+		case f.choices != nil:
+			spec += fmt.Sprintf(":%s:(%s)", f.name, strings.Join(f.choices, " "))
+		case f.isFile:
+			spec += fmt.Sprintf(":%s:_files", f.name)
+		default:
+			spec += fmt.Sprintf(":%s:", f.name)
+		}
+		fmt.Fprintf(w, "        '%s'\n", spec)
+	}
+	fmt.Fprintf(w, "    )\n")
+	fmt.Fprintf(w, "    cmds=(\n")
+	for _, c := range subcommands {
+		fmt.Fprintf(w, "        '%s:%s'\n", c.name, zshQuote(c.help))
+	}
+	fmt.Fprintf(w, "    )\n")
+	fmt.Fprintf(w, "    if (( CURRENT == 2 )) && [[ $words[CURRENT] != -* ]]; then\n")
+	fmt.Fprintf(w, "        _describe command cmds\n")
+	fmt.Fprintf(w, "    elif [[ $words[2] == completion && $words[CURRENT] != -* ]]; then\n")
+	fmt.Fprintf(w, "        _values shell %s\n", strings.Join(completionShells, " "))
+	fmt.Fprintf(w, "    else\n")
+	fmt.Fprintf(w, "        _arguments $opts\n")
+	fmt.Fprintf(w, "    fi\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "compdef %s %s\n", fn, prog)
+}
+
+// fishQuote single-quotes s for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func writeFishCompletion(w io.Writer, prog string) {
+	fmt.Fprintf(w, "# fish completion for %s\n", prog)
+	fmt.Fprintf(w, "complete -c %s -f\n", prog)
+	for _, c := range subcommands {
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n",
+			prog, c.name, fishQuote(c.help))
+	}
+	fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from completion' -a %s\n",
+		prog, fishQuote(strings.Join(completionShells, " ")))
+	for _, f := range completionFlags() {
+		line := fmt.Sprintf("complete -c %s -o %s -d %s", prog, f.name, fishQuote(f.usage))
+		switch {
+		case f.isBool:
+		case f.choices != nil:
+			line += " -x -a " + fishQuote(strings.Join(f.choices, " "))
+		case f.isFile:
+			line += " -r -F"
+		default:
+			line += " -x"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var flagInteractive = flag.Bool("interactive", false,
+	"read commands from standard input while the dinner runs")
+
+// cmdToggle pauses a running dinner, or resumes a paused one.
+// It's what SIGUSR1 sends.
+const cmdToggle = "toggle"
+
+// cmdCheckpoint saves the dinner in the -checkpoint file and stops it.
+// It's what SIGTERM sends, when there's a file to save in.
+const cmdCheckpoint = "checkpoint"
+
+// controller lets something outside the dinner pause, inspect and adjust it.
+//
+// Philosophers hold a read lock from just before reaching for sticks until
+// they've put them back. Holding the write lock therefore means every
+// philosopher is at a safe point: no sticks in hand, counters at rest.
+// A philosopher already inside the lock is allowed to finish its meal,
+// since the sticks it waits for are held only by others who will finish too.
+type controller struct {
+	mu sync.RWMutex
+	// thinking is how long philosophers think between meals; guarded by mu.
+	thinking time.Duration
+
+	// Everything below is only touched by the goroutine running commands.
+	// quiet suppresses messages and snapshots, when a spectator owns the terminal.
+	quiet  bool
+	paused bool
+	speed  float64
+	dt     diningTable
+	chef   *chef
+	sp     spectator
+	killed []bool
+
+	// stopped is closed once a checkpoint has been taken, to send
+	// everyone away from the table; saved is the checkpoint.
+	stopped chan struct{}
+	saved   *checkpoint
+	// scriptRun counts the scenario commands run, so a checkpoint can say
+	// where in its phases the dinner stopped.
+	scriptRun int
+}
+
+func newController(dt diningTable, c *chef, sp spectator, speed float64) *controller {
+	ctl := &controller{
+		quiet:    sp.ownsTerminal(),
+		thinking: time.Duration(float64(ThinkingDuration) / speed),
+		speed:    speed,
+		dt:       dt,
+		chef:     c,
+		sp:       sp,
+		killed:   make([]bool, len(dt)),
+		stopped:  make(chan struct{}),
+	}
+	// A resumed dinner may have philosophers already asked to leave.
+	for i := range dt {
+		select {
+		case <-dt[i].diner.kill:
+			ctl.killed[i] = true
+		default:
+		}
+	}
+	return ctl
+}
+
+// enter is called by a philosopher before reaching for sticks.
+func (c *controller) enter() {
+	c.mu.RLock()
+}
+
+// leave is called by a philosopher after releasing both sticks.
+func (c *controller) leave() {
+	c.mu.RUnlock()
+}
+
+// pause blocks until every philosopher is at a safe point.
+func (c *controller) pause() bool {
+	if c.paused {
+		return false
+	}
+	c.mu.Lock()
+	c.paused = true
+	return true
+}
+
+func (c *controller) resume() bool {
+	if !c.paused {
+		return false
+	}
+	c.paused = false
+	c.mu.Unlock()
+	return true
+}
+
+// run executes commands, and those of a scenario's script, until done is closed.
+// Once a checkpoint is taken, the script is left for the resumed dinner.
+func (c *controller) run(commands, script <-chan string, done <-chan struct{}) {
+	for {
+		select {
+		case line := <-commands:
+			c.execute(line)
+		case line := <-script:
+			c.execute(line)
+			c.scriptRun++
+		case <-done:
+			return
+		}
+		if c.saved != nil {
+			script = nil
+		}
+	}
+}
+
+func (c *controller) execute(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	arg := ""
+	if len(fields) > 1 {
+		arg = fields[1]
+	}
+	switch fields[0] {
+	case "pause":
+		if !c.paused {
+			c.pauseWithSnapshot()
+		}
+	case "resume":
+		if c.resume() {
+			c.say(msgControlResuming)
+		}
+	case cmdToggle:
+		if c.resume() {
+			c.say(msgControlResuming)
+			return
+		}
+		c.pauseWithSnapshot()
+	case "step":
+		c.step()
+	case "stats":
+		wasPaused := c.paused
+		c.pause()
+		c.snapshot(wasPaused)
+		if !wasPaused {
+			c.resume()
+		}
+	case "watch":
+		c.watch(arg)
+	case "kill":
+		c.kill(arg)
+	case "refill":
+		c.refill(arg)
+	case "speed":
+		c.setSpeed(arg)
+	case "faster":
+		c.changeSpeed(c.speed * 2)
+	case "slower":
+		c.changeSpeed(c.speed / 2)
+	case cmdCheckpoint:
+		c.checkpoint()
+	case "help":
+		c.say(msgCommandHelp)
+	default:
+		c.say(msgUnknownCommand, fields[0])
+		c.say(msgTryHelp)
+	}
+}
+
+// say prints a message, unless the controller must be quiet.
+func (c *controller) say(key msgKey, args ...any) {
+	if !c.quiet {
+		fmt.Println(tr(key, args...))
+	}
+}
+
+func (c *controller) pauseWithSnapshot() {
+	c.say(msgControlPausing)
+	c.pause()
+	c.snapshot(true)
+}
+
+// step lets a paused dinner run until the chef hands out one more serving,
+// then pauses it again. With live goroutines rather than a discrete-event
+// engine, others may also eat in the meantime; a step is just the shortest
+// run the dinner can be trusted to make.
+func (c *controller) step() {
+	c.pause()
+	served := c.chef.nextServing()
+	c.resume()
+	select {
+	case <-served:
+	case <-c.chef.emptied:
+	}
+	c.pause()
+	c.say(msgControlStepped)
+}
+
+// checkpoint saves the dinner at a safe point, then sends everyone away
+// from the table without their leaving, so it can be resumed as it was.
+func (c *controller) checkpoint() {
+	if *flagCheckpoint == "" {
+		c.say(msgNoCheckpointFile)
+		return
+	}
+	if c.saved != nil {
+		return
+	}
+	c.say(msgControlStopping)
+	c.pause()
+	c.saved = c.takeCheckpoint()
+	close(c.stopped)
+	c.resume()
+}
+
+// checkpointed returns the checkpoint taken, if any.
+func (c *controller) checkpointed() *checkpoint {
+	select {
+	case <-c.stopped:
+		return c.saved
+	default:
+		return nil
+	}
+}
+
+// snapshot reports on a dinner that must be paused, if only for the moment.
+func (c *controller) snapshot(paused bool) {
+	if c.quiet {
+		return
+	}
+	state := tr(msgRunning)
+	if paused {
+		state = tr(msgPaused)
+	}
+	c.dt.report(tr(msgSnapshot, state, c.chef.servingsLeft(), c.speed))
+}
+
+// philosopherID parses "p17" or "17".
+func (c *controller) philosopherID(arg string) (int, bool) {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "p"))
+	if err != nil || id < 0 || id >= len(c.dt) {
+		c.say(msgNoPhilosopher, arg)
+		return 0, false
+	}
+	return id, true
+}
+
+func (c *controller) watch(arg string) {
+	id := -1
+	if arg != "all" && arg != "" {
+		var ok bool
+		if id, ok = c.philosopherID(arg); !ok {
+			return
+		}
+	}
+	c.sp.focus(id)
+}
+
+func (c *controller) kill(arg string) {
+	id, ok := c.philosopherID(arg)
+	if !ok {
+		return
+	}
+	if c.killed[id] {
+		c.say(msgAlreadyAsked, id)
+		return
+	}
+	c.killed[id] = true
+	close(c.dt[id].diner.kill)
+}
+
+func (c *controller) refill(arg string) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		c.say(msgBadServings, arg)
+		return
+	}
+	if !c.chef.addServings(n) {
+		c.say(msgBowlEmpty)
+		return
+	}
+	c.say(msgAddedServings, n)
+}
+
+func (c *controller) setSpeed(arg string) {
+	speed, err := strconv.ParseFloat(arg, 64)
+	if err != nil || speed <= 0 {
+		c.say(msgBadSpeed, arg)
+		return
+	}
+	c.changeSpeed(speed)
+}
+
+func (c *controller) changeSpeed(speed float64) {
+	wasPaused := c.paused
+	c.pause()
+	c.speed = speed
+	c.thinking = time.Duration(float64(ThinkingDuration) / speed)
+	if !wasPaused {
+		c.resume()
+	}
+	c.say(msgThinkingTakes, c.thinking)
+}
+
+// readCommands sends each line read from r to commands.
+func readCommands(r io.Reader, commands chan<- string) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		commands <- scanner.Text()
+	}
+}
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/rpc"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	flagListen = flag.String("listen", "localhost:7070",
+		"for coordinate, the address to serve the trays and the bowl on")
+	flagCoordinator = flag.String("coordinator", "localhost:7070",
+		"for dine, the address of the coordinator")
+	flagSeats = flag.String("seats", "",
+		"for dine, the philosophers to run, as first-last (default everyone)")
+)
+
+// A distributed dinner is the same dinner with the table in one process,
+// the coordinator, and the philosophers in others, the diners, which reach
+// for sticks and rice over the network. Every philosopher has its own
+// connection, and does exactly what one at a local table does, but each
+// step is a remote call, so network latency shows up in the results.
+// The coordinator knows each connection's seat, so a philosopher can only
+// use its own sticks, and one whose connection drops gives them back.
+
+// TrayPair names a philosopher's left and right trays.
+// Stick i lives in tray i, so these also name the sticks.
+type TrayPair struct {
+	Left, Right int
+}
+
+// RemoteResult is what a philosopher tells the coordinator on leaving.
+type RemoteResult struct {
+	ID    int
+	Waits int
+	Eaten int
+	// Calls counts remote calls, and CallTime is how long they took in all,
+	// including time spent waiting for a stick to come back to its tray.
+	Calls       int
+	CallTime    time.Duration
+	LongestCall time.Duration
+}
+
+// RemoteTable is the coordinator's side of a distributed dinner.
+// Trays are channels, as at a local table.
+type RemoteTable struct {
+	trays  []chan *chopStick
+	sticks []chopStick
+	chef   *chef
+	// joined carries philosophers arriving, and closed the seats whose
+	// connections have closed, after leaving or without.
+	joined chan int
+	closed chan int
+	// seated is closed once everyone has joined.
+	seated chan struct{}
+	mu     sync.Mutex
+	// taken marks seats already joined, and left those that have left;
+	// guarded by mu.
+	taken, left []bool
+	// results holds what each philosopher said on leaving, and lastLeft
+	// when the last one did; guarded by mu.
+	results  []RemoteResult
+	lastLeft time.Time
+	// holder holds, per stick, the seat holding it, or -1; guarded by mu.
+	holder []int
+}
+
+func newRemoteTable(numPhilosophers int, c *chef) *RemoteTable {
+	t := &RemoteTable{
+		trays:   make([]chan *chopStick, numPhilosophers),
+		sticks:  make([]chopStick, numPhilosophers),
+		chef:    c,
+		joined:  make(chan int),
+		closed:  make(chan int, numPhilosophers),
+		seated:  make(chan struct{}),
+		taken:   make([]bool, numPhilosophers),
+		left:    make([]bool, numPhilosophers),
+		results: make([]RemoteResult, numPhilosophers),
+		holder:  make([]int, numPhilosophers),
+	}
+	for i := range t.trays {
+		t.trays[i] = make(chan *chopStick, 1)
+		t.sticks[i].id = i
+		t.holder[i] = -1
+	}
+	return t
+}
+
+// traysOf returns the trays a seat can reach.
+func (t *RemoteTable) traysOf(id int) TrayPair {
+	n := len(t.trays)
+	return TrayPair{Left: (n + id - 1) % n, Right: id}
+}
+
+// hold gives a stick just taken from its tray to a seat, unless the seat
+// has lost its connection, in which case the stick goes back.
+func (t *RemoteTable) hold(seat *RemoteSeat, s *chopStick) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-seat.gone:
+		t.trays[s.id] <- s
+		return fmt.Errorf("seat %d has lost its connection", seat.id)
+	default:
+	}
+	t.holder[s.id] = seat.id
+	s.countGrab++
+	return nil
+}
+
+// drop is called once a connection is closed, and tells the coordinator.
+// If its philosopher hadn't left, any sticks it held go back to their
+// trays, so its neighbors can carry on.
+func (t *RemoteTable) drop(seat *RemoteSeat) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	close(seat.gone)
+	if seat.id < 0 {
+		return
+	}
+	if !t.left[seat.id] {
+		for i, h := range t.holder {
+			if h == seat.id {
+				t.holder[i] = -1
+				t.trays[i] <- &t.sticks[i]
+			}
+		}
+	}
+	t.closed <- seat.id
+}
+
+// RemoteSeat is a connection to the table, served over net/rpc, and the
+// seat its philosopher joined, so every call is known to come from there.
+type RemoteSeat struct {
+	t *RemoteTable
+	// id is the seat joined, or -1 before then; guarded by t.mu.
+	id int
+	// gone is closed when the connection is.
+	gone chan struct{}
+}
+
+func newRemoteSeat(t *RemoteTable) *RemoteSeat {
+	return &RemoteSeat{t: t, id: -1, gone: make(chan struct{})}
+}
+
+// seat returns the seat joined, or an error if there's none yet.
+func (s *RemoteSeat) seat() (int, error) {
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	if s.id < 0 {
+		return 0, errors.New("join a seat first")
+	}
+	return s.id, nil
+}
+
+// Join takes a seat, and returns once every seat is taken.
+func (s *RemoteSeat) Join(id int, _ *struct{}) error {
+	t := s.t
+	if id < 0 || id >= len(t.taken) {
+		return fmt.Errorf("no seat %d", id)
+	}
+	t.mu.Lock()
+	if s.id >= 0 {
+		t.mu.Unlock()
+		return fmt.Errorf("already at seat %d", s.id)
+	}
+	taken := t.taken[id]
+	if !taken {
+		t.taken[id] = true
+		s.id = id
+	}
+	t.mu.Unlock()
+	if taken {
+		return fmt.Errorf("seat %d is taken", id)
+	}
+	t.joined <- id
+	<-t.seated
+	return nil
+}
+
+// TakeEither waits for a stick in either of the seat's trays, and says which it took.
+func (s *RemoteSeat) TakeEither(p TrayPair, stick *int) error {
+	id, err := s.seat()
+	if err != nil {
+		return err
+	}
+	if p != s.t.traysOf(id) {
+		return fmt.Errorf("seat %d can't reach trays %d and %d", id, p.Left, p.Right)
+	}
+	var c *chopStick
+	select {
+	case c = <-s.t.trays[p.Left]:
+	case c = <-s.t.trays[p.Right]:
+	case <-s.gone:
+		return fmt.Errorf("seat %d has lost its connection", id)
+	}
+	if err := s.t.hold(s, c); err != nil {
+		return err
+	}
+	*stick = c.id
+	return nil
+}
+
+// TryTake takes the stick in one of the seat's trays, if it's there.
+func (s *RemoteSeat) TryTake(i int, ok *bool) error {
+	id, err := s.seat()
+	if err != nil {
+		return err
+	}
+	if p := s.t.traysOf(id); i != p.Left && i != p.Right {
+		return fmt.Errorf("seat %d can't reach tray %d", id, i)
+	}
+	select {
+	case c := <-s.t.trays[i]:
+		if err := s.t.hold(s, c); err != nil {
+			return err
+		}
+		*ok = true
+	default:
+		*ok = false
+	}
+	return nil
+}
+
+// Release puts a stick the seat holds back in its tray.
+func (s *RemoteSeat) Release(i int, _ *struct{}) error {
+	id, err := s.seat()
+	if err != nil {
+		return err
+	}
+	t := s.t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if i < 0 || i >= len(t.holder) || t.holder[i] != id {
+		return fmt.Errorf("seat %d doesn't hold stick %d", id, i)
+	}
+	t.holder[i] = -1
+	// Its tray is empty while it's held, so this doesn't block.
+	t.trays[i] <- &t.sticks[i]
+	return nil
+}
+
+// Serve hands a serving to a seat holding both its sticks,
+// or says there's no more food.
+func (s *RemoteSeat) Serve(p TrayPair, ok *bool) error {
+	id, err := s.seat()
+	if err != nil {
+		return err
+	}
+	t := s.t
+	t.mu.Lock()
+	holds := p == t.traysOf(id) && t.holder[p.Left] == id && t.holder[p.Right] == id
+	t.mu.Unlock()
+	if !holds {
+		return fmt.Errorf("seat %d doesn't hold sticks %d and %d", id, p.Left, p.Right)
+	}
+	if _, *ok = <-t.chef.bowl; *ok {
+		t.sticks[p.Left].countEat++
+		t.sticks[p.Right].countEat++
+	}
+	return nil
+}
+
+// Leave is the last call a philosopher makes.
+func (s *RemoteSeat) Leave(r RemoteResult, _ *struct{}) error {
+	id, err := s.seat()
+	if err != nil {
+		return err
+	}
+	if r.ID != id {
+		return fmt.Errorf("seat %d can't leave for philosopher %d", id, r.ID)
+	}
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	s.t.left[id] = true
+	s.t.results[id] = r
+	s.t.lastLeft = time.Now()
+	return nil
+}
+
+// coordinate serves the table of a distributed dinner until every
+// philosopher has joined, eaten what they could, and left.
+func coordinate() error {
+	if NumPhilosophers < 2 {
+		return errors.New(tr(msgTooFew))
+	}
+	l, err := net.Listen("tcp", *flagListen)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	t := newRemoteTable(NumPhilosophers, newChef(NumServings))
+	// Each connection has its own server, so calls are known by their seat.
+	if err := rpc.NewServer().RegisterName("Table", newRemoteSeat(t)); err != nil {
+		return err
+	}
+	// Unlike server.Accept, this stops quietly once the listener is closed.
+	// A connection whose diner dies is closed by its system, or, if the
+	// machine is lost, found dead by the TCP keep-alives Go turns on.
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				seat := newRemoteSeat(t)
+				server := rpc.NewServer()
+				server.RegisterName("Table", seat) // checked above
+				server.ServeConn(conn)
+				t.drop(seat)
+			}()
+		}
+	}()
+
+	fmt.Println(tr(msgCoordinating, NumPhilosophers, l.Addr(), NumServings))
+	for range t.trays {
+		<-t.joined
+	}
+	fmt.Println(tr(msgAllSeated))
+	for i := range t.trays {
+		t.trays[i] <- &t.sticks[i]
+	}
+	start := time.Now()
+	go t.chef.serveRice()
+	close(t.seated)
+
+	// Philosophers leave before their connections close, so waiting for
+	// the connections lets every Leave call get its reply.
+	dropped := make([]bool, NumPhilosophers)
+	for range t.trays {
+		id := <-t.closed
+		t.mu.Lock()
+		dropped[id] = !t.left[id]
+		t.mu.Unlock()
+		if dropped[id] {
+			fmt.Println(tr(msgRemoteDropped, id))
+		}
+	}
+	// Everyone's gone, so the table can be read.
+	results := t.results
+	for id := range results {
+		results[id].ID = id
+	}
+	elapsed := time.Since(start)
+	if !t.lastLeft.IsZero() {
+		elapsed = t.lastLeft.Sub(start)
+	}
+
+	fmt.Println("\n" + tr(msgReport))
+	printBuildInfo()
+	var calls int
+	var callTime, longest time.Duration
+	for _, r := range results {
+		if dropped[r.ID] {
+			fmt.Println(tr(msgRemoteGone, r.ID))
+			continue
+		}
+		fmt.Print(tr(msgRemoteStats, r.ID, r.Waits, r.Eaten, r.Calls, avgCall(r), r.LongestCall))
+		if r.Eaten == 0 {
+			fmt.Print(tr(msgStarved))
+		}
+		fmt.Println()
+		calls += r.Calls
+		callTime += r.CallTime
+		if r.LongestCall > longest {
+			longest = r.LongestCall
+		}
+	}
+	fmt.Println(tr(msgRemoteCalls, calls, avgCall(RemoteResult{Calls: calls, CallTime: callTime}), longest))
+	for i := range t.sticks {
+		fmt.Println(tr(msgStickStats, i, t.sticks[i].countGrab, t.sticks[i].countEat))
+	}
+	fmt.Println(tr(msgRemoteElapsed, elapsed.Round(time.Millisecond)))
+	return nil
+}
+
+func avgCall(r RemoteResult) time.Duration {
+	if r.Calls == 0 {
+		return 0
+	}
+	return r.CallTime / time.Duration(r.Calls)
+}
+
+// remotePhilosopher is a philosopher whose table is somewhere else.
+type remotePhilosopher struct {
+	client *rpc.Client
+	trays  TrayPair
+	result RemoteResult
+}
+
+// call makes a remote call, timing it.
+func (p *remotePhilosopher) call(method string, args, reply any) error {
+	start := time.Now()
+	err := p.client.Call("Table."+method, args, reply)
+	d := time.Since(start)
+	p.result.Calls++
+	p.result.CallTime += d
+	if d > p.result.LongestCall {
+		p.result.LongestCall = d
+	}
+	return err
+}
+
+// eatAndThink is philosopher.eatAndThink, over the network.
+func (p *remotePhilosopher) eatAndThink() error {
+	// Waiting for everyone to join isn't part of the dinner, so isn't timed.
+	if err := p.client.Call("Table.Join", p.result.ID, &struct{}{}); err != nil {
+		return err
+	}
+	for {
+		var first int
+		if err := p.call("TakeEither", p.trays, &first); err != nil {
+			return err
+		}
+		second := p.trays.Left
+		if first == p.trays.Left {
+			second = p.trays.Right
+		}
+		var ok bool
+		if err := p.call("TryTake", second, &ok); err != nil {
+			return err
+		}
+		if !ok {
+			if err := p.call("Release", first, &struct{}{}); err != nil {
+				return err
+			}
+			p.result.Waits++
+			continue
+		}
+		if err := p.call("Serve", p.trays, &ok); err != nil {
+			return err
+		}
+		for _, stick := range []int{p.trays.Left, p.trays.Right} {
+			if err := p.call("Release", stick, &struct{}{}); err != nil {
+				return err
+			}
+		}
+		if !ok {
+			// No more food, time to leave.
+			return p.call("Leave", p.result, &struct{}{})
+		}
+		p.result.Eaten++
+		time.Sleep(ThinkingDuration)
+	}
+}
+
+// dine runs some or all of the philosophers of a distributed dinner,
+// each with its own connection to the coordinator.
+func dine() error {
+	// The protocol is strategyRetry's, spelled out in remote calls.
+	if *flagStrategy != strategyRetry {
+		return fmt.Errorf("dine only supports the %s strategy, not %s", strategyRetry, *flagStrategy)
+	}
+	first, last, err := parseSeats(*flagSeats, NumPhilosophers)
+	if err != nil {
+		return err
+	}
+	var philosophers []*remotePhilosopher
+	for id := first; id <= last; id++ {
+		client, err := rpc.Dial("tcp", *flagCoordinator)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		philosophers = append(philosophers, &remotePhilosopher{
+			client: client,
+			trays:  TrayPair{Left: (NumPhilosophers + id - 1) % NumPhilosophers, Right: id},
+			result: RemoteResult{ID: id},
+		})
+	}
+	fmt.Println(tr(msgDiners, first, last, *flagCoordinator))
+	errs := make(chan error, len(philosophers))
+	for _, p := range philosophers {
+		go func(p *remotePhilosopher) {
+			errs <- p.eatAndThink()
+		}(p)
+	}
+	var firstErr error
+	for range philosophers {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// parseSeats parses "first-last", or a single seat; empty means everyone.
+func parseSeats(seats string, numPhilosophers int) (first, last int, err error) {
+	if seats == "" {
+		return 0, numPhilosophers - 1, nil
+	}
+	from, to, isRange := strings.Cut(seats, "-")
+	if !isRange {
+		to = from
+	}
+	first, err1 := strconv.Atoi(from)
+	last, err2 := strconv.Atoi(to)
+	if err1 != nil || err2 != nil || first < 0 || last < first || last >= numPhilosophers {
+		return 0, 0, fmt.Errorf("bad seats %q; want first-last within 0-%d", seats, numPhilosophers-1)
+	}
+	return first, last, nil
+}
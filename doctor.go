@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// minAddressSpace is the smallest address space limit the doctor accepts
+// without warning; the Go runtime reserves address space well ahead of use.
+const minAddressSpace = 1 << 30
+
+// doctor reports on the runtime environment, and warns about anything
+// that would skew the results of a simulation.
+func doctor() {
+	var warnings []string
+	warn := func(key msgKey, args ...any) {
+		warnings = append(warnings, tr(key, args...))
+	}
+
+	numCPU := runtime.NumCPU()
+	maxProcs := runtime.GOMAXPROCS(0)
+	fmt.Printf("%-14s%d\n", tr(msgDoctorCPUs), numCPU)
+	fmt.Printf("%-14s%d\n", "GOMAXPROCS", maxProcs)
+	if env := os.Getenv("GOMAXPROCS"); env != "" {
+		fmt.Printf("%-14s%s\n", "$GOMAXPROCS", tr(msgDoctorMaxProcsEnv, env))
+	}
+	if numCPU == 1 {
+		warn(msgWarnOneCPU)
+	}
+
+	race := tr(msgDoctorOff)
+	if raceEnabled {
+		race = tr(msgDoctorOn)
+		warn(msgWarnRace)
+	}
+	fmt.Printf("%-14s%s\n", tr(msgDoctorRace), race)
+
+	// SetMaxThreads is the only way to read the limit, so put it right back.
+	maxThreads := debug.SetMaxThreads(10000)
+	debug.SetMaxThreads(maxThreads)
+	fmt.Printf("%-14s%d\n", tr(msgDoctorMaxThreads), maxThreads)
+
+	for _, line := range resourceLimits() {
+		fmt.Println(line)
+	}
+	if limit, ok := addressSpaceLimit(); ok && limit < minAddressSpace {
+		warn(msgWarnAddressSpace, limit)
+	}
+
+	if governor, err := os.ReadFile("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor"); err == nil {
+		g := strings.TrimSpace(string(governor))
+		fmt.Printf("%-14s%s\n", tr(msgDoctorGovernor), g)
+		if g != "performance" {
+			warn(msgWarnGovernor, g)
+		}
+	}
+
+	cols, rows, isTTY := ttySize()
+	color := terminalHasColor()
+	switch {
+	case !isTTY:
+		fmt.Printf("%-14s%s\n", tr(msgDoctorTerminal), tr(msgDoctorNotTerminal))
+	default:
+		hasColor := tr(msgDoctorOff)
+		if color {
+			hasColor = tr(msgDoctorOn)
+		}
+		fmt.Printf("%-14s%s\n", tr(msgDoctorTerminal), tr(msgDoctorTerminalSize, cols, rows, hasColor))
+		if cols < tuiMinCols || rows < tuiMinRows {
+			warn(msgWarnSmallTerminal, tuiMinCols, tuiMinRows)
+		}
+		if !color {
+			warn(msgWarnNoColor, idColor)
+		}
+		if *flagOutput == outputAll {
+			warn(msgWarnOutputAll)
+		}
+	}
+
+	if len(warnings) == 0 {
+		fmt.Println(tr(msgDoctorNoWarnings))
+		return
+	}
+	fmt.Println(tr(msgDoctorWarnings))
+	for _, w := range warnings {
+		fmt.Println("  - " + w)
+	}
+}
+
+// terminalHasColor guesses from the environment whether the terminal shows color.
+func terminalHasColor() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	term := os.Getenv("TERM")
+	return term != "" && term != "dumb"
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+var flagEventLog = flag.String("eventlog", "",
+	"file to record every event in, as JSON lines with the fields of -format, for the export command")
+
+// logHeader is the first line of an event log, saying how big the table
+// is, so events can be checked against it when the log is read back.
+type logHeader struct {
+	Philosophers int
+}
+
+// recorder is a spectator that writes every event to an event log,
+// then hands it on to the spectator it wraps.
+type recorder struct {
+	spectator
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+	// err is the first failure to write the log.
+	err error
+}
+
+// newRecorder starts a new event log, or adds to the end of one.
+func newRecorder(sp spectator, name string, appendTo bool, numPhilosophers int) (*recorder, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendTo {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(name, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	r := &recorder{spectator: sp, f: f, w: w, enc: json.NewEncoder(w)}
+	// A log being added to already has its header.
+	if info, err := f.Stat(); err == nil && info.Size() == 0 {
+		r.err = r.enc.Encode(logHeader{Philosophers: numPhilosophers})
+	}
+	return r, nil
+}
+
+func (r *recorder) watch(events <-chan event) {
+	passOn(r.spectator, events, func(e event) {
+		if r.err == nil {
+			r.err = r.enc.Encode(fieldsOf(e))
+		}
+	})
+}
+
+// close finishes the log, reporting anything that went wrong writing it.
+func (r *recorder) close() error {
+	err := r.err
+	if ferr := r.w.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := r.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("writing event log %s: %v", r.f.Name(), err)
+	}
+	return nil
+}
+
+// readEventLog reads back the events recorded in an event log, and the
+// number of philosophers at the table. Logs written before they had a
+// header are taken to have as many philosophers as they mention.
+func readEventLog(r io.Reader) ([]event, int, error) {
+	var events []event
+	// lines holds the line each event was read from, to say where a bad one is.
+	var lines []int
+	numPhilosophers := -1
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var f eventFields
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			return nil, 0, fmt.Errorf("line %d: %v", line, err)
+		}
+		if f.Action == "" && len(events) == 0 && numPhilosophers < 0 {
+			var h logHeader
+			if err := json.Unmarshal(scanner.Bytes(), &h); err != nil {
+				return nil, 0, fmt.Errorf("line %d: %v", line, err)
+			}
+			if h.Philosophers < 0 {
+				return nil, 0, fmt.Errorf("line %d: can't have %d philosophers", line, h.Philosophers)
+			}
+			numPhilosophers = h.Philosophers
+			continue
+		}
+		act, ok := actionNamed(f.Action)
+		if !ok {
+			return nil, 0, fmt.Errorf("line %d: unknown action %q", line, f.Action)
+		}
+		events = append(events, event{
+			at:    f.Time,
+			id:    f.Philosopher,
+			act:   act,
+			stick: f.Stick,
+			tries: f.Tries,
+			msg:   f.Message,
+		})
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	if numPhilosophers < 0 {
+		numPhilosophers = 0
+		for _, e := range events {
+			if e.id >= numPhilosophers {
+				numPhilosophers = e.id + 1
+			}
+		}
+	}
+	// Stick i is shared by philosophers i and i+1, so there are as many sticks.
+	for i, e := range events {
+		if e.id < 0 || e.id >= numPhilosophers {
+			return nil, 0, fmt.Errorf("line %d: no philosopher %d at a table of %d", lines[i], e.id, numPhilosophers)
+		}
+		if e.stick < -1 || e.stick >= numPhilosophers {
+			return nil, 0, fmt.Errorf("line %d: no stick %d at a table of %d", lines[i], e.stick, numPhilosophers)
+		}
+	}
+	return events, numPhilosophers, nil
+}
+
+// actionNamed is the inverse of action.String.
+func actionNamed(name string) (action, bool) {
+	for act, n := range actionNames {
+		if n == name {
+			return act, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadEventLog(t *testing.T) {
+	const (
+		take  = `{"Time":"2026-01-02T03:04:05Z","Philosopher":1,"Action":"takeRight","Stick":1}`
+		eat   = `{"Time":"2026-01-02T03:04:06Z","Philosopher":0,"Action":"eat","Stick":-1}`
+		third = `{"Time":"2026-01-02T03:04:07Z","Philosopher":2,"Action":"leave","Stick":-1}`
+	)
+	for _, tc := range []struct {
+		name            string
+		log             string
+		events          int
+		numPhilosophers int
+		err             string
+	}{
+		{name: "header only", log: `{"Philosophers":5}`, numPhilosophers: 5},
+		{name: "header", log: `{"Philosophers":5}` + "\n" + take + "\n" + eat, events: 2, numPhilosophers: 5},
+		{name: "no header", log: take + "\n\n" + third, events: 2, numPhilosophers: 3},
+		{name: "empty"},
+		{name: "philosopher out of range", log: `{"Philosophers":2}` + "\n" + eat + "\n" + third,
+			err: "line 3: no philosopher 2 at a table of 2"},
+		{name: "negative philosopher", log: strings.Replace(eat, `"Philosopher":0`, `"Philosopher":-1`, 1),
+			err: "line 1: no philosopher -1"},
+		{name: "stick out of range", log: `{"Philosophers":2}` + "\n" + strings.Replace(take, `"Stick":1`, `"Stick":2`, 1),
+			err: "line 2: no stick 2 at a table of 2"},
+		{name: "stick out of range without header", log: strings.Replace(take, `"Stick":1`, `"Stick":-2`, 1),
+			err: "line 1: no stick -2"},
+		{name: "unknown action", log: strings.Replace(eat, "eat", "dance", 1), err: `line 1: unknown action "dance"`},
+		{name: "bad header", log: `{"Philosophers":-3}`, err: "line 1: can't have -3 philosophers"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			events, n, err := readEventLog(strings.NewReader(tc.log))
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("got error %v, want %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != tc.events || n != tc.numPhilosophers {
+				t.Errorf("got %d events of %d philosophers, want %d of %d", len(events), n, tc.events, tc.numPhilosophers)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	log := `{"Philosophers":3}
+{"Time":"2026-01-02T03:04:05Z","Philosopher":1,"Action":"takeRight","Stick":1}
+{"Time":"2026-01-02T03:04:06Z","Philosopher":1,"Action":"takeLeft","Stick":0}
+{"Time":"2026-01-02T03:04:07Z","Philosopher":1,"Action":"eat","Stick":-1}`
+	for _, tc := range []struct {
+		name   string
+		log    string
+		frames int
+	}{
+		{name: "header only", log: `{"Philosophers":5}`},
+		{name: "dinner", log: log, frames: 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			events, n, err := readEventLog(strings.NewReader(tc.log))
+			if err != nil {
+				t.Fatal(err)
+			}
+			frames := replay(events, n, 4)
+			if len(frames) != tc.frames {
+				t.Fatalf("got %d frames, want %d", len(frames), tc.frames)
+			}
+			if tc.frames == 0 {
+				return
+			}
+			last := frames[len(frames)-1]
+			if last.owner[0] != 1 || last.owner[1] != 1 || last.owner[2] != -1 {
+				t.Errorf("last frame has sticks held by %v, want [1 1 -1]", last.owner)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// Output policies, selected with the -output flag.
+const (
+	// outputAll prints every event, so the simulation can run no faster
+	// than the terminal can scroll.
+	outputAll = "all"
+	// outputCoalesce never prints individual events, only per-philosopher
+	// event rates once per summaryInterval.
+	outputCoalesce = "coalesce"
+	// outputAuto prints every event until more than -maxLineRate lines
+	// arrive in one summaryInterval, or until the terminal falls behind and
+	// events back up, then coalesces until the rate drops below what the
+	// terminal can show.
+	outputAuto = "auto"
+)
+
+// summaryInterval is how often coalesced event rates are printed.
+const summaryInterval = time.Second
+
+var (
+	flagOutput = flag.String("output", outputAuto,
+		"event output policy: "+outputAll+", "+outputCoalesce+" or "+outputAuto)
+	flagMaxLineRate = flag.Int("maxLineRate", 2000,
+		"in auto output, the lines per second above which events are coalesced")
+)
+
+// action is something a philosopher does that's worth reporting.
+type action int
+
+const (
+	actTakeLeft action = iota
+	actTakeRight
+	actRelease
+	actWait
+	actEat
+	actThink
+	actDoneThinking
+	actLeave
+)
+
+// event is one reportable moment in a philosopher's life.
+type event struct {
+	at    time.Time
+	id    int
+	act   action
+	stick int // -1 if no stick is involved.
+	tries int
+	// msg describes the event, without saying who it happened to.
+	msg string
+}
+
+// spectator shows a dinner to someone, using the events philosophers emit.
+type spectator interface {
+	// watch consumes events until the channel is closed,
+	// returning once everything has been shown.
+	watch(events <-chan event)
+	// focus narrows attention to one philosopher, or to everyone if id is negative.
+	focus(id int)
+	// ownsTerminal is true if the spectator draws on the whole terminal,
+	// so nothing else should print while it watches.
+	ownsTerminal() bool
+}
+
+// passOn is the watch of a spectator that wraps another: it sees each
+// event, then hands it on to the wrapped spectator, watching in a goroutine
+// of its own, and returns once that one has shown everything.
+func passOn(wrapped spectator, events <-chan event, see func(event)) {
+	tee := make(chan event, cap(events))
+	watched := make(chan struct{})
+	go func() {
+		wrapped.watch(tee)
+		close(watched)
+	}()
+	for e := range events {
+		see(e)
+		tee <- e
+	}
+	close(tee)
+	<-watched
+}
+
+// printer is the spectator that owns standard output for the duration of a dinner.
+// It decides whether to print events one by one or to coalesce them into rates.
+type printer struct {
+	policy  string
+	maxRate int
+	// tmpl, if not nil, formats each event line.
+	tmpl *template.Template
+	done chan struct{}
+	// focused limits output to one philosopher, or to everyone if negative.
+	focused chan int
+	// counts holds, per philosopher, events received while coalescing.
+	counts []int
+}
+
+func newPrinter(numPhilosophers int, policy string, maxRate int, tmpl *template.Template) *printer {
+	return &printer{
+		policy:  policy,
+		maxRate: maxRate,
+		tmpl:    tmpl,
+		done:    make(chan struct{}),
+		focused: make(chan int),
+		counts:  make([]int, numPhilosophers),
+	}
+}
+
+func validOutputPolicy(policy string) bool {
+	switch policy {
+	case outputAll, outputCoalesce, outputAuto:
+		return true
+	}
+	return false
+}
+
+func (pr *printer) watch(events <-chan event) {
+	defer close(pr.done)
+	ticker := time.NewTicker(summaryInterval)
+	defer ticker.Stop()
+	coalescing := pr.policy == outputCoalesce
+	// arrived counts events to print in this summaryInterval.
+	arrived := 0
+	// limit is the most lines per second to print: -maxLineRate, or less
+	// once the terminal has been seen to manage no more.
+	limit := float64(pr.maxRate)
+	// printed counts lines printed since printingSince.
+	printed, printingSince := 0, time.Now()
+	countStart := time.Now()
+	watched := -1
+	for {
+		select {
+		case watched = <-pr.focused:
+		case e, ok := <-events:
+			if !ok {
+				if coalescing {
+					pr.summarize(time.Since(countStart))
+				}
+				return
+			}
+			if watched >= 0 && e.id != watched {
+				continue
+			}
+			arrived++
+			if !coalescing && pr.policy == outputAuto {
+				// A slow terminal never prints too many lines; it holds up
+				// philosophers, whose events back up waiting to be printed.
+				// A burst can fill the channel too, so it's only a sign of
+				// a slow terminal once a channel's worth has been printed.
+				behind := cap(events) > 0 && len(events) >= cap(events)*3/4 && printed >= cap(events)
+				switch {
+				case behind:
+					limit = float64(printed) / time.Since(printingSince).Seconds()
+					fmt.Println(tr(msgOutputBehind, limit))
+					coalescing = true
+				case arrived > pr.maxRate:
+					fmt.Println(tr(msgCoalescing, pr.maxRate))
+					coalescing = true
+				}
+				if coalescing {
+					countStart = time.Now()
+				}
+			}
+			if coalescing {
+				pr.counts[e.id]++
+				continue
+			}
+			printed++
+			if pr.tmpl != nil {
+				fmt.Println(formatEvent(pr.tmpl, e))
+				continue
+			}
+			fmt.Println(sid(e.id), e.msg)
+		case <-ticker.C:
+			if coalescing {
+				rate := pr.summarize(time.Since(countStart))
+				countStart = time.Now()
+				if pr.policy == outputAuto && rate <= limit {
+					fmt.Println(tr(msgNotCoalescing, int(limit)))
+					coalescing = false
+					printed, printingSince = 0, time.Now()
+				}
+			}
+			arrived = 0
+		}
+	}
+}
+
+// summarize prints the event rate of every philosopher that did something
+// since the last summary, resets the counts, and returns the total rate.
+func (pr *printer) summarize(elapsed time.Duration) float64 {
+	secs := elapsed.Seconds()
+	if secs <= 0 {
+		return 0
+	}
+	total := 0
+	for id, n := range pr.counts {
+		if n == 0 {
+			continue
+		}
+		fmt.Println(sid(id), tr(msgEventRate, float64(n)/secs))
+		total += n
+		pr.counts[id] = 0
+	}
+	rate := float64(total) / secs
+	fmt.Println(tr(msgCoalesced, total, elapsed.Round(time.Millisecond), rate))
+	return rate
+}
+
+func (pr *printer) ownsTerminal() bool { return false }
+
+func (pr *printer) focus(id int) {
+	select {
+	case pr.focused <- id:
+	case <-pr.done:
+	}
+}
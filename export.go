@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var flagFrames = flag.Int("frames", 100, "for export, how many frames the animation has")
+
+// Dimensions of an exported animation, in pixels.
+const (
+	exportSize = 480
+	// exportRadius is the distance from the center to each philosopher.
+	exportRadius = 200
+)
+
+// exportColors are the colors of the philosophers in each state,
+// and of the table and the sticks.
+var (
+	exportColors = map[dinerState]color.RGBA{
+		stateWaiting:  {0xef, 0x6c, 0x00, 0xff},
+		stateEating:   {0x2e, 0x7d, 0x32, 0xff},
+		stateThinking: {0x15, 0x65, 0xc0, 0xff},
+		stateGone:     {0xbd, 0xbd, 0xbd, 0xff},
+	}
+	exportBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	exportTable      = color.RGBA{0xee, 0xee, 0xee, 0xff}
+	exportStick      = color.RGBA{0x33, 0x33, 0x33, 0xff}
+)
+
+// frame is the table at one moment of an animation.
+type frame struct {
+	states []dinerState
+	// owner holds, per stick, the philosopher holding it, or -1 if it's in its tray.
+	owner []int
+}
+
+// export turns the event log named by the first argument into an
+// animation written to the second, an .svg or a .gif file.
+func export(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("export needs an event log and an .svg or .gif file to write")
+	}
+	in, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	events, numPhilosophers, err := readEventLog(in)
+	in.Close()
+	if err != nil {
+		return fmt.Errorf("reading event log %s: %v", args[0], err)
+	}
+	if numPhilosophers < 2 || len(events) == 0 {
+		return fmt.Errorf("event log %s has no dinner in it", args[0])
+	}
+	frames := replay(events, numPhilosophers, *flagFrames)
+
+	var write func(io.Writer, []frame, time.Duration) error
+	switch strings.ToLower(filepath.Ext(args[1])) {
+	case ".svg":
+		write = writeSVG
+	case ".gif":
+		write = writeGIF
+	default:
+		return fmt.Errorf("can only export to .svg or .gif, not %s", args[1])
+	}
+	out, err := os.Create(args[1])
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	err = write(w, frames, frameInterval())
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(tr(msgExportWrote, len(frames), numPhilosophers, args[1]))
+	return nil
+}
+
+// replay applies events, in order, to a view of the table, taking
+// a picture of it at evenly spaced moments from the first event to the last.
+// Without events, there are no pictures.
+func replay(events []event, numPhilosophers, count int) []frame {
+	if len(events) == 0 {
+		return nil
+	}
+	if count < 2 {
+		count = 2
+	}
+	view := newTableView(numPhilosophers)
+	start, end := events[0].at, events[len(events)-1].at
+	frames := make([]frame, count)
+	next := 0
+	for i := range frames {
+		at := start.Add(end.Sub(start) * time.Duration(i) / time.Duration(count-1))
+		for next < len(events) && !events[next].at.After(at) {
+			view.apply(events[next])
+			next++
+		}
+		frames[i] = frame{
+			states: append([]dinerState(nil), view.states...),
+			owner:  append([]int(nil), view.owner...),
+		}
+	}
+	return frames
+}
+
+// point is a position in an exported picture.
+type point struct{ x, y float64 }
+
+// polar returns the point at radius r and angle a, in radians, from the center.
+func polar(r, a float64) point {
+	return point{exportSize/2 + r*math.Sin(a), exportSize/2 - r*math.Cos(a)}
+}
+
+// seatAngle returns the angle, from the top, at which philosopher id sits.
+// Stick i sits halfway between philosopher i and philosopher i+1.
+func seatAngle(id float64, numPhilosophers int) float64 {
+	return 2 * math.Pi * id / float64(numPhilosophers)
+}
+
+// dinerRadius is how big a philosopher is drawn, so neighbors don't touch.
+func dinerRadius(numPhilosophers int) float64 {
+	r := math.Pi * exportRadius / float64(numPhilosophers) * 0.6
+	if r > 16 {
+		r = 16
+	}
+	return r
+}
+
+// stickEnds returns where to draw stick i: in its tray, or
+// moved toward the philosopher holding it.
+func stickEnds(i, owner, numPhilosophers int) (point, point) {
+	a := seatAngle(float64(i)+0.5, numPhilosophers)
+	inner, outer := 0.72, 0.86
+	if owner >= 0 {
+		// The owner is philosopher i, on the left, or philosopher i+1, on the right.
+		shift := 0.3
+		if owner == i {
+			shift = -0.3
+		}
+		a += seatAngle(shift, numPhilosophers)
+		inner, outer = 0.8, 0.94
+	}
+	return polar(inner*exportRadius, a), polar(outer*exportRadius, a)
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// writeSVG writes the frames as an SVG image that animates itself,
+// showing each frame for interval, and repeating forever.
+func writeSVG(w io.Writer, frames []frame, interval time.Duration) error {
+	n := len(frames[0].states)
+	dur := fmt.Sprintf("%.3fs", (interval * time.Duration(len(frames))).Seconds())
+	// animate adds an animation of attr through values, if they ever change.
+	animate := func(attr string, values []string) string {
+		for _, v := range values[1:] {
+			if v != values[0] {
+				return fmt.Sprintf(`<animate attributeName="%s" values="%s" dur="%s" calcMode="discrete" repeatCount="indefinite"/>`,
+					attr, strings.Join(values, ";"), dur)
+			}
+		}
+		return ""
+	}
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		exportSize, exportSize, exportSize, exportSize)
+	fmt.Fprintf(w, "<title>%d dining philosophers</title>\n", n)
+	fmt.Fprintf(w, `<rect width="%d" height="%d" fill="%s"/>`+"\n", exportSize, exportSize, hexColor(exportBackground))
+	fmt.Fprintf(w, `<circle cx="%d" cy="%d" r="%.1f" fill="%s"/>`+"\n",
+		exportSize/2, exportSize/2, 0.9*exportRadius, hexColor(exportTable))
+	r := dinerRadius(n)
+	for id := 0; id < n; id++ {
+		fills := make([]string, len(frames))
+		for i, f := range frames {
+			fills[i] = hexColor(exportColors[f.states[id]])
+		}
+		c := polar(exportRadius, seatAngle(float64(id), n))
+		fmt.Fprintf(w, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s">%s</circle>`+"\n",
+			c.x, c.y, r, fills[0], animate("fill", fills))
+	}
+	for i := 0; i < n; i++ {
+		var x1, y1, x2, y2 []string
+		for _, f := range frames {
+			p, q := stickEnds(i, f.owner[i], n)
+			x1 = append(x1, fmt.Sprintf("%.1f", p.x))
+			y1 = append(y1, fmt.Sprintf("%.1f", p.y))
+			x2 = append(x2, fmt.Sprintf("%.1f", q.x))
+			y2 = append(y2, fmt.Sprintf("%.1f", q.y))
+		}
+		fmt.Fprintf(w, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="2">%s%s%s%s</line>`+"\n",
+			x1[0], y1[0], x2[0], y2[0], hexColor(exportStick),
+			animate("x1", x1), animate("y1", y1), animate("x2", x2), animate("y2", y2))
+	}
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// writeGIF writes the frames as an animated GIF, showing each frame
+// for interval, and repeating forever.
+func writeGIF(w io.Writer, frames []frame, interval time.Duration) error {
+	palette := color.Palette{exportBackground, exportTable, exportStick}
+	index := map[dinerState]uint8{}
+	for _, s := range []dinerState{stateWaiting, stateEating, stateThinking, stateGone} {
+		index[s] = uint8(len(palette))
+		palette = append(palette, exportColors[s])
+	}
+	// GIF delays are in hundredths of a second.
+	delay := int(interval / (10 * time.Millisecond))
+	if delay < 2 {
+		delay = 2
+	}
+	n := len(frames[0].states)
+	r := dinerRadius(n)
+	anim := &gif.GIF{}
+	for _, f := range frames {
+		img := image.NewPaletted(image.Rect(0, 0, exportSize, exportSize), palette)
+		fillDisc(img, polar(0, 0), 0.9*exportRadius, 1)
+		for id, s := range f.states {
+			fillDisc(img, polar(exportRadius, seatAngle(float64(id), n)), r, index[s])
+		}
+		for i, owner := range f.owner {
+			p, q := stickEnds(i, owner, n)
+			drawLine(img, p, q, 2)
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// fillDisc paints a disc of radius r around c with palette color ci.
+func fillDisc(img *image.Paletted, c point, r float64, ci uint8) {
+	for y := int(c.y - r); y <= int(c.y+r); y++ {
+		for x := int(c.x - r); x <= int(c.x+r); x++ {
+			dx, dy := float64(x)-c.x, float64(y)-c.y
+			if dx*dx+dy*dy <= r*r {
+				img.SetColorIndex(x, y, ci)
+			}
+		}
+	}
+}
+
+// drawLine paints a line from p to q with palette color ci.
+func drawLine(img *image.Paletted, p, q point, ci uint8) {
+	steps := int(2 * math.Hypot(q.x-p.x, q.y-p.y))
+	if steps < 1 {
+		steps = 1
+	}
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		img.SetColorIndex(int(p.x+t*(q.x-p.x)), int(p.y+t*(q.y-p.y)), ci)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var flagFairWait = flag.Duration("fairWait", 10*time.Millisecond,
+	"for the fair strategy, the longest a philosopher should wait for sticks; it gets priority at half that")
+
+// strategyFair is strategyRetry, but a philosopher who has waited half
+// of -fairWait reserves both its sticks, so neighbors put them back rather
+// than keep them, and it waits for each in turn. No two reservations share
+// a stick, and nobody else here waits holding one, so a reserving
+// philosopher waits only for its neighbors to finish a meal. That keeps
+// every wait near the bound, at the cost of sticks lying idle while reserved.
+const strategyFair = "fair"
+
+// waitBound is the longest wait the strategy in use promises, or zero
+// if it promises none; it's chosen along with grab.
+var waitBound time.Duration
+
+// boundOf returns the longest wait a strategy promises, if any.
+func boundOf(name string) time.Duration {
+	if name == strategyFair {
+		return *flagFairWait
+	}
+	return 0
+}
+
+// reservation is a pair of sticks reserved for a philosopher, known by
+// the id of its left stick, which nobody else has on their left.
+type reservation struct {
+	leftStick int
+	// cleared is closed once the philosopher has the sticks.
+	cleared chan struct{}
+}
+
+// reservations holds, per stick, the reservation it's in, if any.
+var reservations = struct {
+	mu      sync.Mutex
+	heldFor map[int]*reservation
+}{heldFor: map[int]*reservation{}}
+
+// reserve reserves both of a philosopher's sticks for it, unless
+// either is reserved for someone else.
+func reserve(leftStick, rightStick int) bool {
+	reservations.mu.Lock()
+	defer reservations.mu.Unlock()
+	for _, s := range []int{leftStick, rightStick} {
+		if r, ok := reservations.heldFor[s]; ok && r.leftStick != leftStick {
+			return false
+		}
+	}
+	r := &reservation{leftStick: leftStick, cleared: make(chan struct{})}
+	reservations.heldFor[leftStick] = r
+	reservations.heldFor[rightStick] = r
+	return true
+}
+
+// unreserve frees the sticks a philosopher reserved, now it holds them.
+func unreserve(leftStick, rightStick int) {
+	reservations.mu.Lock()
+	defer reservations.mu.Unlock()
+	close(reservations.heldFor[leftStick].cleared)
+	delete(reservations.heldFor, leftStick)
+	delete(reservations.heldFor, rightStick)
+}
+
+// reservedForOther returns, if a stick is reserved for someone else,
+// a channel closed when they have it; else nil.
+func reservedForOther(stick, leftStick int) <-chan struct{} {
+	reservations.mu.Lock()
+	defer reservations.mu.Unlock()
+	if r, ok := reservations.heldFor[stick]; ok && r.leftStick != leftStick {
+		return r.cleared
+	}
+	return nil
+}
+
+func grabFairly(leftStick, rightStick int,
+	takeEither func() int, take func(side int, wait bool) bool, put func(side int), waited func()) {
+	sticks := [2]int{leftStick, rightStick}
+	hungry := time.Now()
+	for {
+		if time.Since(hungry) >= *flagFairWait/2 && reserve(leftStick, rightStick) {
+			// Nobody else will keep these now, so wait for them, in the
+			// order grabOrdered takes them, so as not to wait in a cycle
+			// with philosophers using it.
+			grabOrdered(leftStick, rightStick, takeEither, take, put, waited)
+			unreserve(leftStick, rightStick)
+			return
+		}
+		first := takeEither()
+		cleared := reservedForOther(sticks[first], leftStick)
+		if cleared == nil {
+			cleared = reservedForOther(sticks[1-first], leftStick)
+		}
+		if cleared == nil && take(1-first, false) {
+			return
+		}
+		put(first)
+		waited()
+		if cleared != nil {
+			// Keep out of the way till the neighbor has its sticks.
+			<-cleared
+			continue
+		}
+		// Let others run, including any neighbor a stick was put back for,
+		// rather than spin while there are fewer CPUs than philosophers.
+		runtime.Gosched()
+	}
+}
+
+// reportWaitBound says whether everyone promised a bounded wait got one.
+func (dt diningTable) reportWaitBound() {
+	var bound time.Duration
+	var over []string
+	for i := range dt {
+		p := &dt[i].diner
+		if p.waitBound == 0 {
+			continue
+		}
+		if p.waitBound > bound {
+			bound = p.waitBound
+		}
+		if p.longestWait > p.waitBound {
+			over = append(over, fmt.Sprintf("p%d (%v)", p.id, p.longestWait.Round(time.Microsecond)))
+		}
+	}
+	if bound == 0 {
+		return
+	}
+	if len(over) == 0 {
+		fmt.Println(tr(msgWaitBoundHonored, bound))
+		return
+	}
+	sort.Strings(over)
+	fmt.Println(tr(msgWaitBoundBroken, bound, len(over), strings.Join(over, ", ")))
+}
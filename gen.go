@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	flagSeed = flag.Int64("seed", 0,
+		"for gen, the random seed (default from the clock); the same seed and bounds make the same scenarios")
+	flagGenDiners   = flag.String("genDiners", "3-30", "for gen, how many philosophers, as min-max")
+	flagGenServings = flag.String("genServings", "5-50", "for gen, servings per philosopher, as min-max")
+	flagGenGroups   = flag.String("genGroups", "1-4", "for gen, how many groups, as min-max")
+	flagGenThinking = flag.String("genThinking", "1ms-20ms",
+		"for gen, how long a group thinks, as min-max, drawn evenly on a log scale")
+	flagGenPhases     = flag.String("genPhases", "0-4", "for gen, how many phases, as min-max")
+	flagGenLength     = flag.Duration("genLength", time.Second, "for gen, how far into the dinner phases can come")
+	flagGenStrategies = flag.String("genStrategies", strategyRetry+","+strategyOrdered,
+		"for gen, the strategies groups may use")
+	flagGenCommands = flag.String("genCommands", "kill,pause,refill,speed",
+		"for gen, what phases may do: kill someone, pause a while, refill the bowl, change speed")
+)
+
+// schools name the groups of a generated scenario.
+var schools = []string{
+	"stoics", "cynics", "epicureans", "skeptics",
+	"sophists", "platonists", "peripatetics", "pythagoreans",
+}
+
+// genBounds limit what gen makes.
+type genBounds struct {
+	diners, servings, groups, phases [2]int
+	thinking                         [2]time.Duration
+	length                           time.Duration
+	strategies, commands             []string
+}
+
+// gen writes a random scenario to each file named, or one to standard output.
+func gen(files []string) error {
+	b, err := readGenBounds()
+	if err != nil {
+		return err
+	}
+	seed := *flagSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for i, name := range files {
+		sc := b.scenario(r)
+		sc.Name = fmt.Sprintf("random %d, seed %d", i+1, seed)
+		if err := sc.validate(); err != nil {
+			// A bug here, not in the bounds, which were checked.
+			return fmt.Errorf("generated a bad scenario: %v", err)
+		}
+		out, err := json.MarshalIndent(sc, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = append(out, '\n')
+		if name == "-" {
+			_, err = os.Stdout.Write(out)
+		} else {
+			err = os.WriteFile(name, out, 0o644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if files[0] != "-" {
+		fmt.Println(tr(msgGenWrote, len(files), seed))
+	}
+	return nil
+}
+
+func readGenBounds() (genBounds, error) {
+	var b genBounds
+	var err error
+	for _, f := range []struct {
+		name, value string
+		to          *[2]int
+		min         int
+	}{
+		{"genDiners", *flagGenDiners, &b.diners, 2},
+		{"genServings", *flagGenServings, &b.servings, 1},
+		{"genGroups", *flagGenGroups, &b.groups, 1},
+		{"genPhases", *flagGenPhases, &b.phases, 0},
+	} {
+		if *f.to, err = parseIntRange(f.value, f.min); err != nil {
+			return b, fmt.Errorf("-%s: %v", f.name, err)
+		}
+	}
+	if b.groups[0] > b.diners[1] {
+		return b, fmt.Errorf("can't have %d groups among at most %d philosophers", b.groups[0], b.diners[1])
+	}
+	lo, hi, _ := strings.Cut(*flagGenThinking, "-")
+	if hi == "" {
+		hi = lo
+	}
+	if b.thinking[0], err = time.ParseDuration(lo); err == nil {
+		b.thinking[1], err = time.ParseDuration(hi)
+	}
+	if err != nil || b.thinking[0] <= 0 || b.thinking[1] < b.thinking[0] {
+		return b, fmt.Errorf("-genThinking: bad range %q; want min-max, like 1ms-20ms", *flagGenThinking)
+	}
+	if b.length = *flagGenLength; b.length <= 0 {
+		return b, errors.New("-genLength must be positive")
+	}
+	b.strategies = strings.Split(*flagGenStrategies, ",")
+	for _, s := range b.strategies {
+		if _, err := lookupStrategy(s); err != nil {
+			return b, fmt.Errorf("-genStrategies: %v", err)
+		}
+	}
+	b.commands = strings.Split(*flagGenCommands, ",")
+	for _, c := range b.commands {
+		switch c {
+		case "kill", "pause", "refill", "speed":
+		default:
+			return b, fmt.Errorf("-genCommands: unknown %q; want kill, pause, refill or speed", c)
+		}
+	}
+	return b, nil
+}
+
+// parseIntRange parses "min-max", or a single number, no less than min.
+func parseIntRange(s string, min int) ([2]int, error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+	if !isRange {
+		hi = lo
+	}
+	a, err1 := strconv.Atoi(lo)
+	b, err2 := strconv.Atoi(hi)
+	if err1 != nil || err2 != nil || a < min || b < a {
+		return [2]int{}, fmt.Errorf("bad range %q; want min-max, from %d up", s, min)
+	}
+	return [2]int{a, b}, nil
+}
+
+// between returns a number in the range, evenly distributed.
+func between(r *rand.Rand, span [2]int) int {
+	return span[0] + r.Intn(span[1]-span[0]+1)
+}
+
+func (b genBounds) scenario(r *rand.Rand) *scenario {
+	n := between(r, b.diners)
+	groups := between(r, b.groups)
+	if groups > n {
+		groups = n
+	}
+	sc := &scenario{
+		Topology: "ring",
+		Servings: n * between(r, b.servings),
+	}
+	// Cut the table into groups at distinct seats, so none is empty.
+	cuts := append(r.Perm(n - 1)[:groups-1], n-1)
+	seats := make([]bool, n)
+	for _, c := range cuts {
+		seats[c] = true
+	}
+	count := 0
+	logLo, logHi := math.Log(float64(b.thinking[0])), math.Log(float64(b.thinking[1]))
+	for i := 0; i < n; i++ {
+		count++
+		if !seats[i] {
+			continue
+		}
+		name := schools[len(sc.Groups)%len(schools)]
+		if len(sc.Groups) >= len(schools) {
+			name += strconv.Itoa(len(sc.Groups)/len(schools) + 1)
+		}
+		thinking := time.Duration(math.Exp(logLo + r.Float64()*(logHi-logLo)))
+		sc.Groups = append(sc.Groups, scenarioGroup{
+			Name:     name,
+			Count:    count,
+			Thinking: duration(thinking.Round(10 * time.Microsecond)),
+			Strategy: b.strategies[r.Intn(len(b.strategies))],
+		})
+		count = 0
+	}
+	for i, phases := 0, between(r, b.phases); i < phases; i++ {
+		at := time.Duration(r.Int63n(int64(b.length))).Round(time.Millisecond)
+		switch b.commands[r.Intn(len(b.commands))] {
+		case "kill":
+			sc.Phases = append(sc.Phases, scenarioPhase{At: duration(at),
+				Commands: []string{fmt.Sprintf("kill p%d", r.Intn(n))}})
+		case "pause":
+			// Every pause is followed by a resume, or the dinner would never end.
+			resume := at + time.Duration(r.Int63n(int64(b.length/10)+1)).Round(time.Millisecond)
+			sc.Phases = append(sc.Phases,
+				scenarioPhase{At: duration(at), Commands: []string{"pause"}},
+				scenarioPhase{At: duration(resume), Commands: []string{"resume"}})
+		case "refill":
+			sc.Phases = append(sc.Phases, scenarioPhase{At: duration(at),
+				Commands: []string{fmt.Sprintf("refill %d", n*between(r, b.servings))}})
+		case "speed":
+			speeds := []string{"0.25", "0.5", "2", "4"}
+			sc.Phases = append(sc.Phases, scenarioPhase{At: duration(at),
+				Commands: []string{"speed " + speeds[r.Intn(len(speeds))]}})
+		}
+	}
+	sc.sortPhases()
+	return sc
+}
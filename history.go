@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	flagRecord  = flag.Bool("record", false, "append this dinner's settings and report to the -history file")
+	flagHistory = flag.String("history", defaultHistoryFile(),
+		"file holding the dinners kept with -record, read by the history command")
+)
+
+// defaultHistoryFile keeps history with the user's configuration,
+// so it's the same file wherever a dinner is run from.
+func defaultHistoryFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "philosophers-history.jsonl"
+	}
+	return filepath.Join(dir, "gophilosophers", "history.jsonl")
+}
+
+// runConfig is what a dinner was asked to do.
+// Dinners have no randomness, so there's no seed; what else varies
+// between runs is the scheduler, and the build, which is in the report.
+type runConfig struct {
+	Command      string        `json:"command"`
+	Args         []string      `json:"args"`
+	Philosophers int           `json:"philosophers"`
+	Servings     int           `json:"servings"`
+	Thinking     time.Duration `json:"thinking"`
+	MaxProcs     int           `json:"maxProcs"`
+	Strategy     string        `json:"strategy"`
+	Scenario     string        `json:"scenario,omitempty"`
+}
+
+// historyEntry is one dinner in the history file, a line of JSON.
+type historyEntry struct {
+	ID      int           `json:"id"`
+	Time    time.Time     `json:"time"`
+	Elapsed time.Duration `json:"elapsed"`
+	Config  runConfig     `json:"config"`
+	Report  jsonReport    `json:"report"`
+}
+
+func currentConfig(cmd string, numPhilosophers, servings int) runConfig {
+	return runConfig{
+		Command:      cmd,
+		Args:         os.Args[1:],
+		Philosophers: numPhilosophers,
+		Servings:     servings,
+		Thinking:     ThinkingDuration,
+		MaxProcs:     runtime.GOMAXPROCS(0),
+		Strategy:     *flagStrategy,
+		Scenario:     *flagScenario,
+	}
+}
+
+func readHistory(name string) ([]historyEntry, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []historyEntry
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var e historyEntry
+		if err := dec.Decode(&e); err != nil {
+			return nil, fmt.Errorf("reading history %s: %v", name, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// recordHistory appends a dinner to the history file, numbering it after the last.
+func recordHistory(name string, e historyEntry) (int, error) {
+	entries, err := readHistory(name)
+	if err != nil {
+		return 0, err
+	}
+	e.ID = 1
+	if len(entries) > 0 {
+		e.ID = entries[len(entries)-1].ID + 1
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	err = json.NewEncoder(f).Encode(e)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return e.ID, err
+}
+
+// history runs "history list", "history show N" or "history compare N M".
+func history(args []string) error {
+	entries, err := readHistory(*flagHistory)
+	if err != nil {
+		return err
+	}
+	find := func(arg string) (historyEntry, error) {
+		id, err := strconv.Atoi(arg)
+		if err == nil {
+			for _, e := range entries {
+				if e.ID == id {
+					return e, nil
+				}
+			}
+		}
+		return historyEntry{}, fmt.Errorf("no dinner %q in %s", arg, *flagHistory)
+	}
+	switch {
+	case len(args) == 0 || args[0] == "list" && len(args) == 1:
+		listHistory(entries)
+	case args[0] == "show" && len(args) == 2:
+		e, err := find(args[1])
+		if err != nil {
+			return err
+		}
+		showHistory(e)
+	case args[0] == "compare" && len(args) == 3:
+		a, err := find(args[1])
+		if err != nil {
+			return err
+		}
+		b, err := find(args[2])
+		if err != nil {
+			return err
+		}
+		compareHistory(a, b)
+	default:
+		return errors.New("history needs one of: list, show N, compare N M")
+	}
+	return nil
+}
+
+// historySummary holds the figures dinners are listed and compared by.
+type historySummary struct {
+	eaten, waits, starved int
+	// throughput is servings eaten per second.
+	throughput float64
+	avgWakeup  time.Duration
+	maxWakeup  time.Duration
+}
+
+func summarize(e historyEntry) historySummary {
+	var s historySummary
+	var wakeups int
+	var wakeupSum time.Duration
+	for _, p := range e.Report.Philosophers {
+		s.eaten += p.Eaten
+		s.waits += p.Waits
+		if p.Starved {
+			s.starved++
+		}
+		wakeups += p.Wakeups
+		wakeupSum += p.AvgWakeup * time.Duration(p.Wakeups)
+		if p.MaxWakeup > s.maxWakeup {
+			s.maxWakeup = p.MaxWakeup
+		}
+	}
+	if e.Elapsed > 0 {
+		s.throughput = float64(s.eaten) / e.Elapsed.Seconds()
+	}
+	if wakeups > 0 {
+		s.avgWakeup = wakeupSum / time.Duration(wakeups)
+	}
+	return s
+}
+
+func listHistory(entries []historyEntry) {
+	if len(entries) == 0 {
+		fmt.Println(tr(msgHistoryEmpty, *flagHistory))
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, tr(msgHistoryColumns))
+	for _, e := range entries {
+		s := summarize(e)
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%v\t%.0f\t%d\t%s\t\n",
+			e.ID, e.Time.Local().Format("2006-01-02 15:04"),
+			e.Config.Philosophers, e.Config.Servings, e.Config.MaxProcs,
+			e.Elapsed.Round(time.Millisecond), s.throughput, s.starved, shortRevision(e.Report.Build))
+	}
+	w.Flush()
+}
+
+func shortRevision(b build) string {
+	r := b.Revision
+	if len(r) > 12 {
+		r = r[:12]
+	}
+	if r == "" {
+		r = "?"
+	}
+	if b.Modified {
+		r += "+"
+	}
+	return r
+}
+
+func showHistory(e historyEntry) {
+	s := summarize(e)
+	fmt.Printf("%-14s%d\n", tr(msgHistoryDinner), e.ID)
+	fmt.Printf("%-14s%s\n", tr(msgHistoryWhen), e.Time.Local().Format(time.RFC3339))
+	fmt.Printf("%-14s%s %v\n", tr(msgHistoryCommand), e.Config.Command, e.Config.Args)
+	fmt.Printf("%-14s%d\n", tr(msgHistoryPhilosophers), e.Config.Philosophers)
+	fmt.Printf("%-14s%d\n", tr(msgHistoryServings), e.Config.Servings)
+	fmt.Printf("%-14s%v\n", tr(msgHistoryThinking), e.Config.Thinking)
+	fmt.Printf("%-14s%d\n", "GOMAXPROCS", e.Config.MaxProcs)
+	fmt.Printf("%-14s%s\n", tr(msgHistoryStrategy), e.Config.Strategy)
+	if e.Config.Scenario != "" {
+		fmt.Printf("%-14s%s\n", tr(msgHistoryScenario), e.Config.Scenario)
+	}
+	fmt.Printf("%-14s%v\n", tr(msgHistoryElapsed), e.Elapsed.Round(time.Millisecond))
+	fmt.Printf("%-14s%s %s, %s, %s/%s\n", tr(msgHistoryBuild),
+		e.Report.Build.Module, shortRevision(e.Report.Build), e.Report.Build.Go, e.Report.Build.OS, e.Report.Build.Arch)
+	fmt.Printf("%-14s%d (%.0f/s)\n", tr(msgHistoryEaten), s.eaten, s.throughput)
+	fmt.Printf("%-14s%d\n", tr(msgHistoryWaits), s.waits)
+	var starved []int
+	for _, p := range e.Report.Philosophers {
+		if p.Starved {
+			starved = append(starved, p.ID)
+		}
+	}
+	fmt.Printf("%-14s%d %v\n", tr(msgHistoryStarved), s.starved, starved)
+	fmt.Printf("%-14s%s\n", tr(msgHistoryWakeups), tr(msgHistoryWakeupStats, s.avgWakeup, s.maxWakeup))
+}
+
+func compareHistory(a, b historyEntry) {
+	sa, sb := summarize(a), summarize(b)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "\t%s\t%s\t%s\t\n", tr(msgHistoryDinnerID, a.ID), tr(msgHistoryDinnerID, b.ID), tr(msgHistoryChange))
+	row := func(name string, x, y float64, format string) {
+		change := "-"
+		if x != 0 {
+			change = fmt.Sprintf("%+.1f%%", (y-x)/x*100)
+		}
+		fmt.Fprintf(w, "%s\t"+format+"\t"+format+"\t%s\t\n", name, x, y, change)
+	}
+	row(tr(msgHistoryPhilosophers), float64(a.Config.Philosophers), float64(b.Config.Philosophers), "%.0f")
+	row(tr(msgHistoryServings), float64(a.Config.Servings), float64(b.Config.Servings), "%.0f")
+	row("GOMAXPROCS", float64(a.Config.MaxProcs), float64(b.Config.MaxProcs), "%.0f")
+	row(tr(msgHistoryElapsedMs), float64(a.Elapsed.Milliseconds()), float64(b.Elapsed.Milliseconds()), "%.0f")
+	row(tr(msgHistoryThroughput), sa.throughput, sb.throughput, "%.0f")
+	row(tr(msgHistoryWaits), float64(sa.waits), float64(sb.waits), "%.0f")
+	row(tr(msgHistoryStarved), float64(sa.starved), float64(sb.starved), "%.0f")
+	row(tr(msgHistoryAvgWakeup), float64(sa.avgWakeup.Microseconds()), float64(sb.avgWakeup.Microseconds()), "%.0f")
+	row(tr(msgHistoryMaxWakeup), float64(sa.maxWakeup.Microseconds()), float64(sb.maxWakeup.Microseconds()), "%.0f")
+	w.Flush()
+	if shortRevision(a.Report.Build) != shortRevision(b.Report.Build) || a.Report.Build.Go != b.Report.Build.Go {
+		fmt.Println(tr(msgHistoryBuildsDiffer,
+			shortRevision(a.Report.Build), a.Report.Build.Go, shortRevision(b.Report.Build), b.Report.Build.Go))
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Ways of showing which philosopher an event line is about, selected with -ids.
+const (
+	// idLane indents each id by its lane, the id modulo -lanes, so neighbors'
+	// lines are easy to tell apart without lines growing with the table.
+	idLane = "lane"
+	// idTag puts every id in a column of fixed width.
+	idTag = "tag"
+	// idColor is idTag with each id colored, cycling through a palette.
+	idColor = "color"
+)
+
+var (
+	flagIDs = flag.String("ids", idLane,
+		"how event lines show philosopher ids: "+idLane+", "+idTag+" or "+idColor)
+	flagLanes = flag.Int("lanes", 8, "number of indentation lanes when -ids="+idLane)
+)
+
+// idPalette holds the ANSI colors idColor cycles through.
+var idPalette = []int{31, 32, 33, 34, 35, 36, 91, 92, 93, 94, 95, 96}
+
+// idRenderer turns a philosopher id into the prefix of an event line.
+type idRenderer struct {
+	style string
+	lanes int
+	// width is the number of digits in the largest id.
+	width int
+}
+
+// ids renders every event line's prefix; chooseIDStyle sets it from -ids and -lanes.
+var ids = idRenderer{style: idLane, lanes: 8, width: 1}
+
+// chooseIDStyle configures ids for a table of numPhilosophers,
+// returning false if the style is unknown.
+func chooseIDStyle(style string, lanes, numPhilosophers int) bool {
+	switch style {
+	case idLane, idTag, idColor:
+	default:
+		return false
+	}
+	if lanes < 1 {
+		lanes = 1
+	}
+	ids = idRenderer{
+		style: style,
+		lanes: lanes,
+		width: len(strconv.Itoa(numPhilosophers - 1)),
+	}
+	return true
+}
+
+func (r idRenderer) render(id int) string {
+	switch r.style {
+	case idTag:
+		return fmt.Sprintf("p%-*d", r.width, id)
+	case idColor:
+		return fmt.Sprintf("\x1b[%dmp%-*d\x1b[0m", idPalette[id%len(idPalette)], r.width, id)
+	}
+	return strings.Repeat("  ", id%r.lanes+1) + fmt.Sprintf("p%-*d", r.width, id)
+}
+
+// sid is the philosopher id as shown in event lines, to facilitate reading
+// interleaved go routine output.
+func sid(id int) string {
+	return ids.render(id)
+}
@@ -0,0 +1,77 @@
+package main
+
+import "time"
+
+// jsonReport is a report in a form other programs can read.
+type jsonReport struct {
+	Build build `json:"build"`
+	// Final is true once everyone has left the table. A report made while
+	// the dinner runs is pieced together from events, so it has no wakeup
+	// latencies or stick counts.
+	Final        bool              `json:"final"`
+	Philosophers []jsonPhilosopher `json:"philosophers"`
+	Sticks       []jsonStick       `json:"sticks,omitempty"`
+}
+
+type jsonPhilosopher struct {
+	ID      int  `json:"id"`
+	Waits   int  `json:"waits"`
+	Eaten   int  `json:"eaten"`
+	Starved bool `json:"starved"`
+	Wakeups int  `json:"wakeups,omitempty"`
+	// Wakeup latencies are in nanoseconds.
+	AvgWakeup time.Duration `json:"avgWakeup,omitempty"`
+	MaxWakeup time.Duration `json:"maxWakeup,omitempty"`
+	// LongestWait is the longest wait for sticks, in nanoseconds.
+	LongestWait time.Duration `json:"longestWait,omitempty"`
+}
+
+type jsonStick struct {
+	ID    int `json:"id"`
+	Grabs int `json:"grabs"`
+	Eats  int `json:"eats"`
+}
+
+// finalReport reports on a table everyone has left.
+func (dt diningTable) finalReport() jsonReport {
+	r := jsonReport{
+		Build:        readBuild(),
+		Final:        true,
+		Philosophers: make([]jsonPhilosopher, len(dt)),
+		Sticks:       make([]jsonStick, len(dt)),
+	}
+	for i := range dt {
+		p := &dt[i].diner
+		r.Philosophers[i] = jsonPhilosopher{
+			ID:          p.id,
+			Waits:       p.hadToWaitCount,
+			Eaten:       p.servingsEatenCount,
+			Starved:     p.servingsEatenCount == 0,
+			Wakeups:     p.wakeupCount,
+			AvgWakeup:   p.avgWakeupLatency(),
+			MaxWakeup:   p.wakeupLatencyMax,
+			LongestWait: p.longestWait,
+		}
+		s := &dt[i].stick
+		r.Sticks[i] = jsonStick{ID: s.id, Grabs: s.countGrab, Eats: s.countEat}
+	}
+	return r
+}
+
+// interimReport reports on a dinner still running, as seen in view.
+// Philosophers still at the table haven't starved yet, however hungry.
+func (v *tableView) interimReport() jsonReport {
+	r := jsonReport{
+		Build:        readBuild(),
+		Philosophers: make([]jsonPhilosopher, len(v.states)),
+	}
+	for id := range v.states {
+		r.Philosophers[id] = jsonPhilosopher{
+			ID:      id,
+			Waits:   v.waits[id],
+			Eaten:   v.eaten[id],
+			Starved: v.states[id] == stateGone && v.eaten[id] == 0,
+		}
+	}
+	return r
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+// keyboard holds what the tui and animate spectators share in handling keys.
+type keyboard struct {
+	// keys delivers key presses; nil if the keyboard isn't in use.
+	keys <-chan byte
+	// commands go to the controller.
+	commands chan<- string
+	// focused is the philosopher to single out, or -1 for everyone.
+	focused int
+	// verbose shows every event, rather than just meals and departures.
+	verbose bool
+}
+
+func newKeyboard(keys <-chan byte, commands chan<- string, verbose bool) keyboard {
+	return keyboard{keys: keys, commands: commands, focused: -1, verbose: verbose}
+}
+
+// press acts on a key. Keys that change the dinner become controller
+// commands; the rest change what's shown of view.
+func (kb *keyboard) press(k byte, view *tableView) {
+	switch k {
+	case '+', '=':
+		kb.send("faster")
+	case '-', '_':
+		kb.send("slower")
+	case 'p', ' ':
+		kb.send(cmdToggle)
+	case 's':
+		kb.send("step")
+	case 'v':
+		kb.verbose = !kb.verbose
+	case 'h':
+		kb.focused = view.hungriest()
+	case 'a':
+		kb.focused = -1
+	}
+}
+
+// send hands cmd to the controller without waiting for it to be taken,
+// since the controller may itself be waiting on philosophers who are
+// waiting for the spectator to take their events.
+func (kb *keyboard) send(cmd string) {
+	go func() {
+		kb.commands <- cmd
+	}()
+}
+
+// shows reports whether an event is of interest at the current verbosity and focus.
+func (kb *keyboard) shows(e event) bool {
+	if kb.focused >= 0 && e.id != kb.focused {
+		return false
+	}
+	return kb.verbose || e.act == actEat || e.act == actLeave
+}
+
+// readKeys sends every byte read from r to keys.
+func readKeys(r io.Reader, keys chan<- byte) {
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		if n == 1 {
+			keys <- buf[0]
+		}
+	}
+}
+
+// useKeyboard puts the terminal in a mode where keys arrive as they're
+// pressed, and starts reading them. The returned func puts the terminal back.
+// An interrupt does the same before exiting, since the terminal would otherwise
+// be left without echo, and perhaps on the alternate screen.
+func useKeyboard() (<-chan byte, func()) {
+	restoreTTY, ok := cbreak()
+	if !ok {
+		return nil, func() {}
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		restoreTTY()
+		fmt.Print(escShowCursor + escAltScreenOff)
+		os.Exit(1)
+	}()
+	ch := make(chan byte)
+	go readKeys(os.Stdin, ch)
+	return ch, restoreTTY
+}
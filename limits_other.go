@@ -0,0 +1,14 @@
+//go:build !(linux || darwin)
+
+package main
+
+import "fmt"
+
+// resourceLimits can't read limits here.
+func resourceLimits() []string {
+	return []string{fmt.Sprintf("%-14s%s", tr(msgDoctorLimits), tr(msgDoctorLimitsUnknown))}
+}
+
+func addressSpaceLimit() (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,53 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// rlimInfinity is syscall.RLIM_INFINITY as the uint64 in a syscall.Rlimit;
+// it's a negative constant on some systems, so it can't be converted directly.
+var rlimInfinity = func() uint64 {
+	infinity := int64(syscall.RLIM_INFINITY)
+	return uint64(infinity)
+}()
+
+// resourceLimits describes the limits that matter to a run with many goroutines.
+func resourceLimits() []string {
+	limits := []struct {
+		name     msgKey
+		resource int
+	}{
+		{msgDoctorOpenFiles, syscall.RLIMIT_NOFILE},
+		{msgDoctorStack, syscall.RLIMIT_STACK},
+		{msgDoctorAddressSpace, syscall.RLIMIT_AS},
+	}
+	var lines []string
+	for _, l := range limits {
+		var r syscall.Rlimit
+		if err := syscall.Getrlimit(l.resource, &r); err != nil {
+			lines = append(lines, fmt.Sprintf("%-14s%v", tr(l.name), err))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%-14s%s", tr(l.name), tr(msgDoctorLimit, rlimitString(r.Cur), rlimitString(r.Max))))
+	}
+	return lines
+}
+
+// addressSpaceLimit returns the soft limit on address space, if there is one.
+func addressSpaceLimit() (uint64, bool) {
+	var r syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_AS, &r); err != nil || r.Cur == rlimInfinity {
+		return 0, false
+	}
+	return r.Cur, true
+}
+
+func rlimitString(v uint64) string {
+	if v == rlimInfinity {
+		return tr(msgDoctorUnlimited)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	flagProcs = flag.String("procs", "",
+		"for matrix, comma separated GOMAXPROCS values (default powers of two up to the cpu count)")
+	flagSizes = flag.String("sizes", "5,20,50,200",
+		"for matrix, comma separated numbers of philosophers")
+	flagServingsEach = flag.Int("servingsEach", 20,
+		"for matrix, servings in the bowl per philosopher")
+	flagReps = flag.Int("reps", 3, "for matrix, runs averaged into each cell")
+)
+
+// heatGlyphs shade a cell from the lowest value in a table to the highest.
+var heatGlyphs = []rune(" ░▒▓█")
+
+// nobody is the spectator of a dinner no one watches.
+type nobody struct{}
+
+func (nobody) watch(events <-chan event) {
+	for range events {
+	}
+}
+
+func (nobody) focus(int) {}
+
+// ownsTerminal is true, so nothing is printed while the dinner runs.
+func (nobody) ownsTerminal() bool { return true }
+
+// matrixCell holds what's measured for one GOMAXPROCS value and table size.
+type matrixCell struct {
+	// throughput is servings eaten per second.
+	throughput float64
+	// waits is how often, on average, a philosopher failed to get both sticks.
+	waits float64
+}
+
+// runMatrix serves the same dinner - the same thinking time, and the same
+// servings per philosopher - for every combination of GOMAXPROCS value and
+// table size, and prints how throughput and waiting scale.
+// There's no randomness in a dinner to seed; what varies between reruns is
+// the scheduler, which is why each cell averages several runs.
+func runMatrix() error {
+	// Every dinner measured uses -strategy, so strategies can be compared.
+	if err := chooseStrategy(*flagStrategy); err != nil {
+		return err
+	}
+	procs, err := matrixProcs()
+	if err != nil {
+		return err
+	}
+	sizes, err := parseInts(*flagSizes)
+	if err != nil {
+		return err
+	}
+	for _, n := range sizes {
+		if n < 2 {
+			return errors.New(tr(msgTooFew))
+		}
+	}
+	reps := *flagReps
+	if reps < 1 {
+		reps = 1
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	cells := make([][]matrixCell, len(sizes))
+	for i, n := range sizes {
+		cells[i] = make([]matrixCell, len(procs))
+		for j, p := range procs {
+			runtime.GOMAXPROCS(p)
+			for r := 0; r < reps; r++ {
+				c := measureDinner(n, n**flagServingsEach)
+				cells[i][j].throughput += c.throughput / float64(reps)
+				cells[i][j].waits += c.waits / float64(reps)
+			}
+			fmt.Println(tr(msgMatrixRun, p, n, cells[i][j].throughput, cells[i][j].waits))
+		}
+	}
+	fmt.Println()
+	printHeatmap(tr(msgMatrixThroughput), procs, sizes, cells,
+		func(c matrixCell) float64 { return c.throughput }, "%10.0f")
+	fmt.Println()
+	printHeatmap(tr(msgMatrixWaits), procs, sizes, cells,
+		func(c matrixCell) float64 { return c.waits }, "%10.1f")
+	fmt.Println()
+	printBuildInfo()
+	return nil
+}
+
+// measureDinner serves one unwatched dinner and measures it.
+func measureDinner(numPhilosophers, numServings int) matrixCell {
+	dt := makeDiningTable(numPhilosophers)
+	ch := newChef(numServings)
+	start := time.Now()
+	dt.serveDinner(ch, nobody{}, make(chan string), nil, 1)
+	elapsed := time.Since(start)
+	eaten, waits := 0, 0
+	for i := range dt {
+		eaten += dt[i].diner.servingsEatenCount
+		waits += dt[i].diner.hadToWaitCount
+	}
+	return matrixCell{
+		throughput: float64(eaten) / elapsed.Seconds(),
+		waits:      float64(waits) / float64(numPhilosophers),
+	}
+}
+
+// printHeatmap prints a table with a row per table size and a column per
+// GOMAXPROCS value, each value followed by a glyph shading it against the rest.
+func printHeatmap(title string, procs, sizes []int, cells [][]matrixCell,
+	value func(matrixCell) float64, format string) {
+	lo, hi := value(cells[0][0]), value(cells[0][0])
+	for _, row := range cells {
+		for _, c := range row {
+			v := value(c)
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+	}
+	corner := tr(msgMatrixCorner)
+	width := utf8.RuneCountInString(corner)
+	if width < 8 {
+		width = 8
+	}
+	fmt.Println(title)
+	fmt.Print(corner)
+	for _, p := range procs {
+		fmt.Printf("%10d  ", p)
+	}
+	fmt.Println()
+	for i, n := range sizes {
+		fmt.Printf("%*d", width, n)
+		for _, c := range cells[i] {
+			v := value(c)
+			shade := 0
+			if hi > lo {
+				shade = int((v - lo) / (hi - lo) * float64(len(heatGlyphs)-1))
+			}
+			fmt.Printf(format+" %c", v, heatGlyphs[shade])
+		}
+		fmt.Println()
+	}
+}
+
+// matrixProcs returns the GOMAXPROCS values given by -procs or,
+// by default, the powers of two up to the number of cpus, and the number itself.
+func matrixProcs() ([]int, error) {
+	if *flagProcs != "" {
+		return parseInts(*flagProcs)
+	}
+	var procs []int
+	numCPU := runtime.NumCPU()
+	for p := 1; p < numCPU; p *= 2 {
+		procs = append(procs, p)
+	}
+	return append(procs, numCPU), nil
+}
+
+// parseInts parses a comma separated list of positive numbers.
+func parseInts(list string) ([]int, error) {
+	var ints []int
+	for _, field := range strings.Split(list, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("bad number %q in %q", field, list)
+		}
+		ints = append(ints, n)
+	}
+	return ints, nil
+}
@@ -0,0 +1,812 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var flagLang = flag.String("lang", "",
+	"language of events and reports: en, de or es (default from $LC_ALL, $LC_MESSAGES or $LANG)")
+
+// msgKey identifies a user-facing message in the catalogs.
+type msgKey int
+
+const (
+	// Events.
+	msgTakesLeft msgKey = iota
+	msgTakesLeftHasBoth
+	msgTakesRight
+	msgTakesRightHasBoth
+	msgReleases
+	msgWhyNoRight
+	msgWhyNoLeft
+	msgWhyNoFood
+	msgWhyAte
+	msgCantGetSticks
+	msgEats
+	msgStartsThinking
+	msgDoneThinking
+	msgAskedToLeave
+	msgLeaves
+
+	// Reports.
+	msgReport
+	msgSnapshot
+	msgRunning
+	msgPaused
+	msgPhilosopherStats
+	msgStarved
+	msgStickStats
+	msgNoWakeups
+	msgWakeups
+	msgLongestWait
+	msgWaitBoundHonored
+	msgWaitBoundBroken
+	msgBuildModule
+	msgBuildRevision
+	msgBuildModified
+	msgBuildGo
+	msgBuildTags
+	msgBuildUnknown
+
+	// Output coalescing.
+	msgCoalescing
+	msgNotCoalescing
+	msgOutputBehind
+	msgEventRate
+	msgCoalesced
+
+	// Progress of the program.
+	msgVersion
+	msgNumCpus
+	msgMaxCpus
+	msgGoroutinesBefore
+	msgGoroutinesStarted
+	msgPlacingStick
+	msgTooFew
+	msgStarvationCertain
+	msgAllDone
+	msgUnknownCommand
+	msgUnknownPolicy
+	msgUnknownIDStyle
+	msgBadTemplate
+	msgUnknownLanguage
+	msgUnknownNotify
+
+	// Notifications.
+	msgNotifyStarved
+	msgNotifyStalled
+	msgNotifyDone
+	msgNotifyCheckpointed
+
+	// Distributed dinners.
+	msgCoordinating
+	msgAllSeated
+	msgDiners
+	msgRemoteStats
+	msgRemoteCalls
+	msgRemoteElapsed
+	msgRemoteDropped
+	msgRemoteGone
+
+	// Checkpoints.
+	msgCheckpointSaved
+	msgResuming
+
+	// Scenarios.
+	msgScenario
+
+	// The teach command.
+	msgTeachKeys
+	msgTeachBoth
+	msgTeachOtherFree
+	msgTeachOtherHeld
+	msgTeachReleased
+	msgTeachWait
+	msgTeachEat
+	msgTeachThink
+	msgTeachHungry
+	msgTeachLeave
+	msgTeachCycle
+
+	// Timelines.
+	msgTimeline
+	msgTimelineLegend
+
+	// History.
+	msgRecorded
+	msgHistoryEmpty
+	msgHistoryColumns
+	msgHistoryDinner
+	msgHistoryWhen
+	msgHistoryCommand
+	msgHistoryPhilosophers
+	msgHistoryServings
+	msgHistoryThinking
+	msgHistoryStrategy
+	msgHistoryScenario
+	msgHistoryElapsed
+	msgHistoryBuild
+	msgHistoryEaten
+	msgHistoryWaits
+	msgHistoryStarved
+	msgHistoryWakeups
+	msgHistoryWakeupStats
+	msgHistoryDinnerID
+	msgHistoryChange
+	msgHistoryElapsedMs
+	msgHistoryThroughput
+	msgHistoryAvgWakeup
+	msgHistoryMaxWakeup
+	msgHistoryBuildsDiffer
+
+	// The controller.
+	msgCommandHelp
+	msgTryHelp
+	msgControlResuming
+	msgControlPausing
+	msgControlStepped
+	msgNoCheckpointFile
+	msgControlStopping
+	msgNoPhilosopher
+	msgAlreadyAsked
+	msgBadServings
+	msgBowlEmpty
+	msgAddedServings
+	msgBadSpeed
+	msgThinkingTakes
+
+	// The tui and animate commands.
+	msgKeyHelp
+	msgTUITitle
+	msgTUILegend
+	msgTUIRice
+	msgTUIElapsed
+	msgTUIServings
+	msgTUIEvents
+	msgTUIEating
+	msgTUIThinking
+	msgTUIWaiting
+	msgTUIGone
+	msgTUIUnfed
+	msgTUIHungriest
+	msgTUIWatching
+	msgTUIRecent
+	msgAnimateStatus
+
+	// The doctor, gen and export commands.
+	msgDoctorCPUs
+	msgDoctorMaxProcsEnv
+	msgDoctorRace
+	msgDoctorOn
+	msgDoctorOff
+	msgDoctorMaxThreads
+	msgDoctorOpenFiles
+	msgDoctorStack
+	msgDoctorAddressSpace
+	msgDoctorLimit
+	msgDoctorUnlimited
+	msgDoctorLimits
+	msgDoctorLimitsUnknown
+	msgDoctorGovernor
+	msgDoctorTerminal
+	msgDoctorNotTerminal
+	msgDoctorTerminalSize
+	msgWarnOneCPU
+	msgWarnRace
+	msgWarnAddressSpace
+	msgWarnGovernor
+	msgWarnSmallTerminal
+	msgWarnNoColor
+	msgWarnOutputAll
+	msgDoctorNoWarnings
+	msgDoctorWarnings
+	msgGenWrote
+	msgExportWrote
+
+	// The matrix command.
+	msgMatrixRun
+	msgMatrixThroughput
+	msgMatrixWaits
+	msgMatrixCorner
+)
+
+// catalogs holds, per language, a fmt format for every message.
+// English is complete; other languages fall back to it for anything missing.
+var catalogs = map[string]map[msgKey]string{
+	"en": {
+		msgTakesLeft:         "takes stick %d from left.",
+		msgTakesLeftHasBoth:  "takes stick %d from left; now has both (%d tries).",
+		msgTakesRight:        "takes stick %d from right.",
+		msgTakesRightHasBoth: "takes stick %d from right; now has both (%d tries).",
+		msgReleases:          "releases stick %d; %s.",
+		msgWhyNoRight:        "got left, but unable to get right",
+		msgWhyNoLeft:         "got right, but unable to get left",
+		msgWhyNoFood:         "no more food",
+		msgWhyAte:            "ate one serving",
+		msgCantGetSticks:     "unable to get chopsticks in %d consecutive attempts.",
+		msgEats:              "eats!",
+		msgStartsThinking:    "has eaten %d bites; starting to think.",
+		msgDoneThinking:      "done thinking.",
+		msgAskedToLeave:      "was asked to leave the table.",
+		msgLeaves:            "leaves the table.",
+
+		msgReport:           "Report:",
+		msgSnapshot:         "Snapshot (%s, %d servings left, speed %g):",
+		msgRunning:          "running",
+		msgPaused:           "paused",
+		msgPhilosopherStats: "philosopher%3d waited%4d times, ate%4d times, woke%4d times (avg%10v, max%10v)  ",
+		msgStarved:          " STARVED!",
+		msgStickStats:       "stick%3d grabbed%4d times, used to eat%4d times",
+		msgNoWakeups:        "no scheduler wakeups measured",
+		msgWakeups:          "scheduler wakeups%6d, avg latency%10v, max latency%10v",
+		msgLongestWait:      "longest wait for sticks %v, by philosopher %d",
+		msgWaitBoundHonored: "every wait for sticks was within the bound of %v",
+		msgWaitBoundBroken:  "the bound of %v on waiting for sticks was broken by %d: %s",
+		msgBuildModule:      "module   %s %s",
+		msgBuildRevision:    "revision %s %s",
+		msgBuildModified:    " (modified)",
+		msgBuildGo:          "go       %s %s/%s",
+		msgBuildTags:        "tags     %s",
+		msgBuildUnknown:     "no build information",
+
+		msgCoalescing:    "More than %d lines/sec; coalescing output.",
+		msgNotCoalescing: "Fewer than %d lines/sec; printing every event.",
+		msgOutputBehind:  "Output is falling behind, at %.0f lines/sec; coalescing output.",
+		msgEventRate:     "%.0f events/sec",
+		msgCoalesced:     "coalesced %d events over %v (%.0f events/sec)",
+
+		msgVersion:             "version = %s",
+		msgNumCpus:             "num cpus = %d",
+		msgMaxCpus:             "max cpus = %d",
+		msgGoroutinesBefore:    "Before any 'go' starts, numGoroutine = %d",
+		msgGoroutinesStarted:   "Philosophers started, numGoroutine = %d",
+		msgPlacingStick:        "Placing chopstick %d",
+		msgTooFew:              "Indexing scheme demands at least 2 philosophers.",
+		msgStarvationCertain:   "Starvation certain.",
+		msgAllDone:             "All done.",
+		msgUnknownCommand:      "Unknown command %q.",
+		msgUnknownPolicy:       "Unknown output policy %q.",
+		msgUnknownIDStyle:      "Unknown id style %q.",
+		msgBadTemplate:         "Bad event template: %v",
+		msgUnknownLanguage:     "No messages in language %q; using English.",
+		msgUnknownNotify:       "Unknown notification style %q.",
+		msgNotifyStarved:       "Philosopher %d left without eating.",
+		msgNotifyStalled:       "Nobody has eaten for %v, though everyone keeps trying.",
+		msgNotifyDone:          "The dinner is over.",
+		msgNotifyCheckpointed:  "The dinner stopped at a checkpoint, to be resumed.",
+		msgCoordinating:        "Waiting for %d philosophers at %s; %d servings in the bowl.",
+		msgAllSeated:           "Everyone is seated; serving the rice.",
+		msgDiners:              "Philosophers %d to %d dining at %s.",
+		msgRemoteStats:         "philosopher%3d waited%4d times, ate%4d times, called%6d times (avg%10v, max%10v)  ",
+		msgRemoteCalls:         "remote calls%8d, avg time%10v, max time%10v",
+		msgRemoteElapsed:       "The dinner took %v.",
+		msgRemoteDropped:       "Philosopher %d lost its connection; its sticks are back in their trays.",
+		msgRemoteGone:          "philosopher%3d lost its connection",
+		msgCheckpointSaved:     "Stopped; saved in %s. Carry on with -resume %s.",
+		msgResuming:            "Resuming from %s, with %d servings left.",
+		msgScenario:            "Scenario %q: %d philosophers in %d groups, %d phases.",
+		msgTeachKeys:           "Each step is one event. Press a key for the next, or r to run to the end.",
+		msgTeachBoth:           "p%d has both its sticks, %d and %d, so it can eat. Safe: it holds them only while it eats.",
+		msgTeachOtherFree:      "Its other stick, %d, is in its tray, so p%d can take it next.",
+		msgTeachOtherHeld:      "p%d holds stick %d and wants stick %d, held by p%d — potential circular wait.",
+		msgTeachReleased:       "Stick %d is back in its tray, for p%d or p%d to take.",
+		msgTeachWait:           "p%d couldn't get both sticks, so it put back the one it had (try %d). Holding nothing while it waits, it can't be part of a deadlock, but it can go hungry.",
+		msgTeachEat:            "p%d has eaten %d servings; everyone, %d.",
+		msgTeachThink:          "p%d thinks, holding no sticks, so its neighbors can use them.",
+		msgTeachHungry:         "p%d is hungry again, and reaches for its sticks.",
+		msgTeachLeave:          "p%d leaves the table; its sticks stay for its neighbors.",
+		msgTeachCycle:          "Circular wait: %s. Each holds one stick and wants the next one's; unless someone puts theirs back, this is a deadlock.",
+		msgTimeline:            "Timeline, %v:",
+		msgTimelineLegend:      "%s eating  %s thinking  %s waiting",
+		msgRecorded:            "recorded as dinner %d in %s",
+		msgHistoryEmpty:        "no dinners in %s; run one with -record",
+		msgHistoryColumns:      "id\twhen\tdiners\tservings\tprocs\telapsed\tservings/s\tstarved\trevision\t",
+		msgHistoryDinner:       "dinner",
+		msgHistoryWhen:         "when",
+		msgHistoryCommand:      "command",
+		msgHistoryPhilosophers: "philosophers",
+		msgHistoryServings:     "servings",
+		msgHistoryThinking:     "thinking",
+		msgHistoryStrategy:     "strategy",
+		msgHistoryScenario:     "scenario",
+		msgHistoryElapsed:      "elapsed",
+		msgHistoryBuild:        "build",
+		msgHistoryEaten:        "eaten",
+		msgHistoryWaits:        "waits",
+		msgHistoryStarved:      "starved",
+		msgHistoryWakeups:      "wakeups",
+		msgHistoryWakeupStats:  "avg %v, max %v",
+		msgHistoryDinnerID:     "dinner %d",
+		msgHistoryChange:       "change",
+		msgHistoryElapsedMs:    "elapsed ms",
+		msgHistoryThroughput:   "servings/s",
+		msgHistoryAvgWakeup:    "avg wakeup µs",
+		msgHistoryMaxWakeup:    "max wakeup µs",
+		msgHistoryBuildsDiffer: "builds differ: %s %s, %s %s",
+		msgCommandHelp: `commands:
+  pause       pause everyone at their next safe point
+  resume      resume a paused dinner
+  toggle      pause if running, else resume
+  stats       print a snapshot report
+  watch pN    only show events from philosopher N ("watch all" to undo)
+  kill pN     ask philosopher N to leave the table
+  refill N    add N servings to the bowl
+  speed X     scale thinking speed, e.g. 0.5 thinks twice as long
+  faster      double the speed
+  slower      halve the speed
+  step        from a pause, run until the next serving is taken, then pause
+  checkpoint  save the dinner in the -checkpoint file and stop it
+  help        show this list`,
+		msgTryHelp:             "Try \"help\".",
+		msgControlResuming:     "Resuming.",
+		msgControlPausing:      "Pausing at next safe point...",
+		msgControlStepped:      "Stepped; paused again.",
+		msgNoCheckpointFile:    "There's no -checkpoint file to save the dinner in.",
+		msgControlStopping:     "Stopping at next safe point...",
+		msgNoPhilosopher:       "No philosopher %q.",
+		msgAlreadyAsked:        "Philosopher %d was already asked to leave.",
+		msgBadServings:         "Bad serving count %q.",
+		msgBowlEmpty:           "Too late; the bowl is already empty.",
+		msgAddedServings:       "Added %d servings.",
+		msgBadSpeed:            "Bad speed %q.",
+		msgThinkingTakes:       "Thinking now takes %v.",
+		msgKeyHelp:             "+ faster  - slower  p pause  s step  v verbosity  h hungriest  a everyone",
+		msgTUITitle:            "Dining philosophers - %d at the table",
+		msgTUILegend:           "E eating  T thinking  W waiting  x gone   | stick in tray  < > held by previous/next",
+		msgTUIRice:             "rice: %d",
+		msgTUIElapsed:          "elapsed   %v",
+		msgTUIServings:         "servings  %d eaten",
+		msgTUIEvents:           "events    %d (%.0f/sec)",
+		msgTUIEating:           "eating    %d",
+		msgTUIThinking:         "thinking  %d",
+		msgTUIWaiting:          "waiting   %d",
+		msgTUIGone:             "gone      %d",
+		msgTUIUnfed:            "unfed     %d",
+		msgTUIHungriest:        "hungriest p%d (waited %d, ate %d)",
+		msgTUIWatching:         "watching  p%d (waited %d, ate %d)",
+		msgTUIRecent:           "recent events:",
+		msgAnimateStatus:       "rice left %d, eaten %d, eating %d, waiting %d",
+		msgDoctorCPUs:          "cpus",
+		msgDoctorMaxProcsEnv:   "%s, though dinners use every cpu",
+		msgDoctorRace:          "race detector",
+		msgDoctorOn:            "on",
+		msgDoctorOff:           "off",
+		msgDoctorMaxThreads:    "max threads",
+		msgDoctorOpenFiles:     "open files",
+		msgDoctorStack:         "stack",
+		msgDoctorAddressSpace:  "address space",
+		msgDoctorLimit:         "%s (hard %s)",
+		msgDoctorUnlimited:     "unlimited",
+		msgDoctorLimits:        "limits",
+		msgDoctorLimitsUnknown: "unknown on this system",
+		msgDoctorGovernor:      "cpu governor",
+		msgDoctorTerminal:      "terminal",
+		msgDoctorNotTerminal:   "not a terminal",
+		msgDoctorTerminalSize:  "%dx%d, color %s",
+		msgWarnOneCPU:          "only one cpu: philosophers take turns rather than contend in parallel",
+		msgWarnRace:            "the race detector slows everything down several times; timings and contention will be skewed",
+		msgWarnAddressSpace:    "address space is limited to %d bytes; the Go runtime may fail to grow the heap",
+		msgWarnGovernor:        "cpu frequency scaling (%s governor) makes timings vary from run to run",
+		msgWarnSmallTerminal:   "the terminal is smaller than %dx%d; tui will be cropped",
+		msgWarnNoColor:         "the terminal doesn't show color; tui and -ids=%s lose their meaning",
+		msgWarnOutputAll:       "printing every event to a terminal limits the dinner to the speed of the terminal",
+		msgDoctorNoWarnings:    "no warnings",
+		msgDoctorWarnings:      "warnings:",
+		msgGenWrote:            "wrote %d scenarios with seed %d",
+		msgExportWrote:         "wrote %d frames of %d philosophers to %s",
+		msgMatrixRun:           "GOMAXPROCS %d, %d philosophers: %.0f servings/s, %.1f waits each",
+		msgMatrixThroughput:    "Servings eaten per second:",
+		msgMatrixWaits:         "Times each philosopher had to wait:",
+		msgMatrixCorner:        "diners\\procs",
+	},
+	"de": {
+		msgTakesLeft:         "nimmt Stäbchen %d von links.",
+		msgTakesLeftHasBoth:  "nimmt Stäbchen %d von links; hat jetzt beide (%d Versuche).",
+		msgTakesRight:        "nimmt Stäbchen %d von rechts.",
+		msgTakesRightHasBoth: "nimmt Stäbchen %d von rechts; hat jetzt beide (%d Versuche).",
+		msgReleases:          "legt Stäbchen %d ab; %s.",
+		msgWhyNoRight:        "hat das linke, bekommt aber das rechte nicht",
+		msgWhyNoLeft:         "hat das rechte, bekommt aber das linke nicht",
+		msgWhyNoFood:         "kein Essen mehr",
+		msgWhyAte:            "hat eine Portion gegessen",
+		msgCantGetSticks:     "bekommt seit %d Versuchen keine Stäbchen.",
+		msgEats:              "isst!",
+		msgStartsThinking:    "hat %d Bissen gegessen; beginnt zu denken.",
+		msgDoneThinking:      "ist fertig mit Denken.",
+		msgAskedToLeave:      "wurde gebeten, den Tisch zu verlassen.",
+		msgLeaves:            "verlässt den Tisch.",
+
+		msgReport:           "Bericht:",
+		msgSnapshot:         "Momentaufnahme (%s, %d Portionen übrig, Tempo %g):",
+		msgRunning:          "läuft",
+		msgPaused:           "pausiert",
+		msgPhilosopherStats: "Philosoph%3d wartete%4d mal, aß%4d mal, geweckt%4d mal (Mittel%10v, max%10v)  ",
+		msgStarved:          " VERHUNGERT!",
+		msgStickStats:       "Stäbchen%3d:%4d mal genommen,%4d mal zum Essen benutzt",
+		msgNoWakeups:        "keine Weckvorgänge des Schedulers gemessen",
+		msgWakeups:          "Weckvorgänge%6d, mittlere Latenz%10v, maximale Latenz%10v",
+		msgLongestWait:      "längstes Warten auf Stäbchen %v, von Philosoph %d",
+		msgWaitBoundHonored: "jedes Warten auf Stäbchen blieb unter der Grenze von %v",
+		msgWaitBoundBroken:  "die Grenze von %v für das Warten auf Stäbchen überschritten von %d: %s",
+		msgBuildModule:      "Modul    %s %s",
+		msgBuildRevision:    "Revision %s %s",
+		msgBuildModified:    " (verändert)",
+		msgBuildGo:          "Go       %s %s/%s",
+		msgBuildTags:        "Tags     %s",
+		msgBuildUnknown:     "keine Build-Informationen",
+
+		msgCoalescing:    "Mehr als %d Zeilen/s; Ausgabe wird zusammengefasst.",
+		msgNotCoalescing: "Weniger als %d Zeilen/s; jedes Ereignis wird ausgegeben.",
+		msgOutputBehind:  "Die Ausgabe kommt mit %.0f Zeilen/s nicht nach; Ausgabe wird zusammengefasst.",
+		msgEventRate:     "%.0f Ereignisse/s",
+		msgCoalesced:     "%d Ereignisse in %v zusammengefasst (%.0f Ereignisse/s)",
+
+		msgVersion:             "Version = %s",
+		msgNumCpus:             "Anzahl CPUs = %d",
+		msgMaxCpus:             "maximale CPUs = %d",
+		msgGoroutinesBefore:    "Vor dem ersten 'go': numGoroutine = %d",
+		msgGoroutinesStarted:   "Philosophen gestartet, numGoroutine = %d",
+		msgPlacingStick:        "Lege Stäbchen %d hin",
+		msgTooFew:              "Die Nummerierung verlangt mindestens 2 Philosophen.",
+		msgStarvationCertain:   "Verhungern ist sicher.",
+		msgAllDone:             "Fertig.",
+		msgUnknownCommand:      "Unbekannter Befehl %q.",
+		msgUnknownPolicy:       "Unbekannte Ausgaberegel %q.",
+		msgUnknownIDStyle:      "Unbekannte Darstellung der Nummern %q.",
+		msgBadTemplate:         "Fehlerhafte Ereignisvorlage: %v",
+		msgUnknownLanguage:     "Keine Meldungen in Sprache %q; verwende Englisch.",
+		msgUnknownNotify:       "Unbekannte Benachrichtigungsart %q.",
+		msgNotifyStarved:       "Philosoph %d ist gegangen, ohne zu essen.",
+		msgNotifyStalled:       "Seit %v hat niemand gegessen, obwohl alle es versuchen.",
+		msgNotifyDone:          "Das Essen ist vorbei.",
+		msgNotifyCheckpointed:  "Das Essen wurde an einem Checkpoint angehalten und wird fortgesetzt.",
+		msgCoordinating:        "Warte auf %d Philosophen an %s; %d Portionen in der Schüssel.",
+		msgAllSeated:           "Alle sitzen; der Reis wird serviert.",
+		msgDiners:              "Philosophen %d bis %d essen an %s.",
+		msgRemoteStats:         "Philosoph%3d wartete%4d mal, aß%4d mal, rief%6d mal an (Mittel%10v, max%10v)  ",
+		msgRemoteCalls:         "Fernaufrufe%8d, mittlere Dauer%10v, maximale Dauer%10v",
+		msgRemoteElapsed:       "Das Essen dauerte %v.",
+		msgRemoteDropped:       "Philosoph %d hat die Verbindung verloren; seine Stäbchen liegen wieder in ihren Ablagen.",
+		msgRemoteGone:          "Philosoph%3d hat die Verbindung verloren",
+		msgCheckpointSaved:     "Angehalten; gespeichert in %s. Weiter mit -resume %s.",
+		msgResuming:            "Fortsetzung von %s, mit %d übrigen Portionen.",
+		msgScenario:            "Szenario %q: %d Philosophen in %d Gruppen, %d Phasen.",
+		msgTeachKeys:           "Jeder Schritt ist ein Ereignis. Eine Taste für den nächsten, oder r, um bis zum Ende zu laufen.",
+		msgTeachBoth:           "p%d hat beide Stäbchen, %d und %d, und kann essen. Sicher: Es hält sie nur, solange es isst.",
+		msgTeachOtherFree:      "Sein anderes Stäbchen, %d, liegt in seiner Ablage, also kann p%d es als Nächstes nehmen.",
+		msgTeachOtherHeld:      "p%d hält Stäbchen %d und will Stäbchen %d, das p%d hält — mögliches zyklisches Warten.",
+		msgTeachReleased:       "Stäbchen %d liegt wieder in seiner Ablage, für p%d oder p%d.",
+		msgTeachWait:           "p%d bekam nicht beide Stäbchen und legte seines zurück (Versuch %d). Wer beim Warten nichts hält, kann an keiner Verklemmung beteiligt sein, aber hungrig bleiben.",
+		msgTeachEat:            "p%d hat %d Portionen gegessen; alle zusammen %d.",
+		msgTeachThink:          "p%d denkt und hält keine Stäbchen, also können seine Nachbarn sie benutzen.",
+		msgTeachHungry:         "p%d hat wieder Hunger und greift nach seinen Stäbchen.",
+		msgTeachLeave:          "p%d verlässt den Tisch; seine Stäbchen bleiben für die Nachbarn.",
+		msgTeachCycle:          "Zyklisches Warten: %s. Jeder hält ein Stäbchen und will das des Nächsten; legt niemand seines zurück, ist das eine Verklemmung.",
+		msgTimeline:            "Zeitleiste, %v:",
+		msgTimelineLegend:      "%s isst  %s denkt  %s wartet",
+		msgRecorded:            "als Essen %d in %s festgehalten",
+		msgHistoryEmpty:        "keine Essen in %s; halten Sie eines mit -record fest",
+		msgHistoryColumns:      "Nr\tWann\tGäste\tPortionen\tProcs\tDauer\tPortionen/s\tverhungert\tRevision\t",
+		msgHistoryDinner:       "Essen",
+		msgHistoryWhen:         "Wann",
+		msgHistoryCommand:      "Befehl",
+		msgHistoryPhilosophers: "Philosophen",
+		msgHistoryServings:     "Portionen",
+		msgHistoryThinking:     "Denken",
+		msgHistoryStrategy:     "Strategie",
+		msgHistoryScenario:     "Szenario",
+		msgHistoryElapsed:      "Dauer",
+		msgHistoryBuild:        "Build",
+		msgHistoryEaten:        "gegessen",
+		msgHistoryWaits:        "gewartet",
+		msgHistoryStarved:      "verhungert",
+		msgHistoryWakeups:      "Weckvorgänge",
+		msgHistoryWakeupStats:  "Mittel %v, max %v",
+		msgHistoryDinnerID:     "Essen %d",
+		msgHistoryChange:       "Änderung",
+		msgHistoryElapsedMs:    "Dauer ms",
+		msgHistoryThroughput:   "Portionen/s",
+		msgHistoryAvgWakeup:    "Mittl. Wecken µs",
+		msgHistoryMaxWakeup:    "Max. Wecken µs",
+		msgHistoryBuildsDiffer: "Builds unterscheiden sich: %s %s, %s %s",
+		msgCommandHelp: `Befehle:
+  pause       alle am nächsten sicheren Punkt anhalten
+  resume      ein angehaltenes Essen fortsetzen
+  toggle      anhalten, wenn es läuft, sonst fortsetzen
+  stats       eine Momentaufnahme ausgeben
+  watch pN    nur Ereignisse von Philosoph N zeigen ("watch all" hebt das auf)
+  kill pN     Philosoph N bitten, den Tisch zu verlassen
+  refill N    N Portionen in die Schüssel geben
+  speed X     Denktempo skalieren, z. B. denkt 0.5 doppelt so lange
+  faster      Tempo verdoppeln
+  slower      Tempo halbieren
+  step        aus einer Pause bis zur nächsten Portion laufen, dann anhalten
+  checkpoint  das Essen in der -checkpoint-Datei speichern und beenden
+  help        diese Liste zeigen`,
+		msgTryHelp:             "Siehe \"help\".",
+		msgControlResuming:     "Weiter geht's.",
+		msgControlPausing:      "Anhalten am nächsten sicheren Punkt...",
+		msgControlStepped:      "Ein Schritt; wieder angehalten.",
+		msgNoCheckpointFile:    "Es gibt keine -checkpoint-Datei, um das Essen zu speichern.",
+		msgControlStopping:     "Beenden am nächsten sicheren Punkt...",
+		msgNoPhilosopher:       "Kein Philosoph %q.",
+		msgAlreadyAsked:        "Philosoph %d wurde schon gebeten zu gehen.",
+		msgBadServings:         "Ungültige Portionenzahl %q.",
+		msgBowlEmpty:           "Zu spät; die Schüssel ist schon leer.",
+		msgAddedServings:       "%d Portionen hinzugefügt.",
+		msgBadSpeed:            "Ungültiges Tempo %q.",
+		msgThinkingTakes:       "Denken dauert jetzt %v.",
+		msgKeyHelp:             "+ schneller  - langsamer  p Pause  s Schritt  v Ausführlichkeit  h Hungrigster  a alle",
+		msgTUITitle:            "Philosophen beim Essen - %d am Tisch",
+		msgTUILegend:           "E isst  T denkt  W wartet  x gegangen   | Stäbchen in Ablage  < > gehalten von vorigem/nächstem",
+		msgTUIRice:             "Reis: %d",
+		msgTUIElapsed:          "Dauer     %v",
+		msgTUIServings:         "Portionen %d gegessen",
+		msgTUIEvents:           "Ereignisse %d (%.0f/s)",
+		msgTUIEating:           "essen     %d",
+		msgTUIThinking:         "denken    %d",
+		msgTUIWaiting:          "warten    %d",
+		msgTUIGone:             "gegangen  %d",
+		msgTUIUnfed:            "ungefüttert %d",
+		msgTUIHungriest:        "hungrigster p%d (wartete %d, aß %d)",
+		msgTUIWatching:         "beobachtet p%d (wartete %d, aß %d)",
+		msgTUIRecent:           "letzte Ereignisse:",
+		msgAnimateStatus:       "Reis übrig %d, gegessen %d, essen %d, warten %d",
+		msgDoctorCPUs:          "CPUs",
+		msgDoctorMaxProcsEnv:   "%s, aber Essen nutzen jede CPU",
+		msgDoctorRace:          "Race-Detektor",
+		msgDoctorOn:            "an",
+		msgDoctorOff:           "aus",
+		msgDoctorMaxThreads:    "max. Threads",
+		msgDoctorOpenFiles:     "Dateilimit",
+		msgDoctorStack:         "Stack",
+		msgDoctorAddressSpace:  "Adressraum",
+		msgDoctorLimit:         "%s (hart %s)",
+		msgDoctorUnlimited:     "unbegrenzt",
+		msgDoctorLimits:        "Grenzen",
+		msgDoctorLimitsUnknown: "auf diesem System unbekannt",
+		msgDoctorGovernor:      "CPU-Governor",
+		msgDoctorTerminal:      "Terminal",
+		msgDoctorNotTerminal:   "kein Terminal",
+		msgDoctorTerminalSize:  "%dx%d, Farbe %s",
+		msgWarnOneCPU:          "nur eine CPU: Philosophen wechseln sich ab, statt parallel zu konkurrieren",
+		msgWarnRace:            "der Race-Detektor verlangsamt alles um ein Mehrfaches; Zeiten und Konkurrenz werden verzerrt",
+		msgWarnAddressSpace:    "der Adressraum ist auf %d Bytes begrenzt; die Go-Laufzeit kann den Heap womöglich nicht vergrößern",
+		msgWarnGovernor:        "CPU-Taktanpassung (Governor %s) lässt Zeiten von Lauf zu Lauf schwanken",
+		msgWarnSmallTerminal:   "das Terminal ist kleiner als %dx%d; tui wird abgeschnitten",
+		msgWarnNoColor:         "das Terminal zeigt keine Farben; tui und -ids=%s verlieren ihre Bedeutung",
+		msgWarnOutputAll:       "jedes Ereignis auf ein Terminal auszugeben bremst das Essen auf das Tempo des Terminals",
+		msgDoctorNoWarnings:    "keine Warnungen",
+		msgDoctorWarnings:      "Warnungen:",
+		msgGenWrote:            "%d Szenarien mit Startwert %d geschrieben",
+		msgExportWrote:         "%d Bilder von %d Philosophen nach %s geschrieben",
+		msgMatrixRun:           "GOMAXPROCS %d, %d Philosophen: %.0f Portionen/s, je %.1f Mal gewartet",
+		msgMatrixThroughput:    "Gegessene Portionen pro Sekunde:",
+		msgMatrixWaits:         "Wie oft jeder Philosoph warten musste:",
+		msgMatrixCorner:        "Esser\\Procs",
+	},
+	"es": {
+		msgTakesLeft:         "toma el palillo %d de la izquierda.",
+		msgTakesLeftHasBoth:  "toma el palillo %d de la izquierda; ya tiene los dos (%d intentos).",
+		msgTakesRight:        "toma el palillo %d de la derecha.",
+		msgTakesRightHasBoth: "toma el palillo %d de la derecha; ya tiene los dos (%d intentos).",
+		msgReleases:          "suelta el palillo %d; %s.",
+		msgWhyNoRight:        "tiene el izquierdo, pero no consigue el derecho",
+		msgWhyNoLeft:         "tiene el derecho, pero no consigue el izquierdo",
+		msgWhyNoFood:         "no queda comida",
+		msgWhyAte:            "comió una porción",
+		msgCantGetSticks:     "no consigue palillos tras %d intentos seguidos.",
+		msgEats:              "¡come!",
+		msgStartsThinking:    "ha comido %d bocados; empieza a pensar.",
+		msgDoneThinking:      "termina de pensar.",
+		msgAskedToLeave:      "fue invitado a dejar la mesa.",
+		msgLeaves:            "deja la mesa.",
+
+		msgReport:           "Informe:",
+		msgSnapshot:         "Instantánea (%s, quedan %d porciones, velocidad %g):",
+		msgRunning:          "en marcha",
+		msgPaused:           "en pausa",
+		msgPhilosopherStats: "filósofo%3d esperó%4d veces, comió%4d veces, despertó%4d veces (media%10v, máx%10v)  ",
+		msgStarved:          " ¡MURIÓ DE HAMBRE!",
+		msgStickStats:       "palillo%3d tomado%4d veces, usado para comer%4d veces",
+		msgNoWakeups:        "no se midió ningún despertar del planificador",
+		msgWakeups:          "despertares%6d, latencia media%10v, latencia máxima%10v",
+		msgLongestWait:      "espera más larga por palillos %v, del filósofo %d",
+		msgWaitBoundHonored: "toda espera por palillos quedó dentro del límite de %v",
+		msgWaitBoundBroken:  "el límite de %v de espera por palillos lo superaron %d: %s",
+		msgBuildModule:      "módulo   %s %s",
+		msgBuildRevision:    "revisión %s %s",
+		msgBuildModified:    " (modificada)",
+		msgBuildGo:          "go       %s %s/%s",
+		msgBuildTags:        "etiquetas %s",
+		msgBuildUnknown:     "sin información de compilación",
+
+		msgCoalescing:    "Más de %d líneas/s; se resume la salida.",
+		msgNotCoalescing: "Menos de %d líneas/s; se muestra cada evento.",
+		msgOutputBehind:  "La salida se queda atrás, a %.0f líneas/s; se resume la salida.",
+		msgEventRate:     "%.0f eventos/s",
+		msgCoalesced:     "%d eventos resumidos en %v (%.0f eventos/s)",
+
+		msgVersion:             "versión = %s",
+		msgNumCpus:             "número de cpus = %d",
+		msgMaxCpus:             "máximo de cpus = %d",
+		msgGoroutinesBefore:    "Antes de cualquier 'go', numGoroutine = %d",
+		msgGoroutinesStarted:   "Filósofos en marcha, numGoroutine = %d",
+		msgPlacingStick:        "Colocando el palillo %d",
+		msgTooFew:              "La numeración exige al menos 2 filósofos.",
+		msgStarvationCertain:   "Alguien morirá de hambre.",
+		msgAllDone:             "Terminado.",
+		msgUnknownCommand:      "Orden desconocida %q.",
+		msgUnknownPolicy:       "Política de salida desconocida %q.",
+		msgUnknownIDStyle:      "Estilo de identificador desconocido %q.",
+		msgBadTemplate:         "Plantilla de eventos incorrecta: %v",
+		msgUnknownLanguage:     "No hay mensajes en el idioma %q; se usa inglés.",
+		msgUnknownNotify:       "Estilo de aviso desconocido %q.",
+		msgNotifyStarved:       "El filósofo %d se fue sin comer.",
+		msgNotifyStalled:       "Nadie ha comido en %v, aunque todos lo intentan.",
+		msgNotifyDone:          "La cena ha terminado.",
+		msgNotifyCheckpointed:  "La cena se detuvo en un punto de control, para reanudarse.",
+		msgCoordinating:        "Esperando a %d filósofos en %s; %d raciones en el cuenco.",
+		msgAllSeated:           "Todos sentados; se sirve el arroz.",
+		msgDiners:              "Filósofos %d a %d cenando en %s.",
+		msgRemoteStats:         "filósofo%3d esperó%4d veces, comió%4d veces, llamó%6d veces (media%10v, máx%10v)  ",
+		msgRemoteCalls:         "llamadas remotas%8d, duración media%10v, duración máxima%10v",
+		msgRemoteElapsed:       "La cena duró %v.",
+		msgRemoteDropped:       "El filósofo %d perdió la conexión; sus palillos vuelven a sus bandejas.",
+		msgRemoteGone:          "filósofo%3d perdió la conexión",
+		msgCheckpointSaved:     "Detenida; guardada en %s. Continúe con -resume %s.",
+		msgResuming:            "Reanudando desde %s, con %d raciones restantes.",
+		msgScenario:            "Escenario %q: %d filósofos en %d grupos, %d fases.",
+		msgTeachKeys:           "Cada paso es un evento. Pulse una tecla para el siguiente, o r para seguir hasta el final.",
+		msgTeachBoth:           "p%d tiene sus dos palillos, %d y %d, así que puede comer. Seguro: solo los retiene mientras come.",
+		msgTeachOtherFree:      "Su otro palillo, %d, está en su bandeja, así que p%d puede tomarlo a continuación.",
+		msgTeachOtherHeld:      "p%d tiene el palillo %d y quiere el palillo %d, que tiene p%d — posible espera circular.",
+		msgTeachReleased:       "El palillo %d vuelve a su bandeja, para p%d o p%d.",
+		msgTeachWait:           "p%d no pudo conseguir ambos palillos y devolvió el que tenía (intento %d). Sin retener nada mientras espera, no puede formar parte de un interbloqueo, pero puede pasar hambre.",
+		msgTeachEat:            "p%d ha comido %d raciones; todos juntos, %d.",
+		msgTeachThink:          "p%d piensa sin retener palillos, así que sus vecinos pueden usarlos.",
+		msgTeachHungry:         "p%d vuelve a tener hambre y busca sus palillos.",
+		msgTeachLeave:          "p%d deja la mesa; sus palillos quedan para sus vecinos.",
+		msgTeachCycle:          "Espera circular: %s. Cada uno tiene un palillo y quiere el del siguiente; si nadie devuelve el suyo, es un interbloqueo.",
+		msgTimeline:            "Cronología, %v:",
+		msgTimelineLegend:      "%s come  %s piensa  %s espera",
+		msgRecorded:            "registrada como cena %d en %s",
+		msgHistoryEmpty:        "no hay cenas en %s; registre una con -record",
+		msgHistoryColumns:      "id\tcuándo\tcomensales\traciones\tprocs\tduración\traciones/s\thambrientos\trevisión\t",
+		msgHistoryDinner:       "cena",
+		msgHistoryWhen:         "cuándo",
+		msgHistoryCommand:      "comando",
+		msgHistoryPhilosophers: "filósofos",
+		msgHistoryServings:     "raciones",
+		msgHistoryThinking:     "pensar",
+		msgHistoryStrategy:     "estrategia",
+		msgHistoryScenario:     "escenario",
+		msgHistoryElapsed:      "duración",
+		msgHistoryBuild:        "compilación",
+		msgHistoryEaten:        "comidas",
+		msgHistoryWaits:        "esperas",
+		msgHistoryStarved:      "hambrientos",
+		msgHistoryWakeups:      "despertares",
+		msgHistoryWakeupStats:  "media %v, máx %v",
+		msgHistoryDinnerID:     "cena %d",
+		msgHistoryChange:       "cambio",
+		msgHistoryElapsedMs:    "duración ms",
+		msgHistoryThroughput:   "raciones/s",
+		msgHistoryAvgWakeup:    "despertar medio µs",
+		msgHistoryMaxWakeup:    "despertar máx µs",
+		msgHistoryBuildsDiffer: "las compilaciones difieren: %s %s, %s %s",
+		msgCommandHelp: `órdenes:
+  pause       detener a todos en su próximo punto seguro
+  resume      reanudar una cena detenida
+  toggle      detener si está en marcha, si no, reanudar
+  stats       mostrar un informe instantáneo
+  watch pN    mostrar solo los eventos del filósofo N ("watch all" lo deshace)
+  kill pN     pedir al filósofo N que deje la mesa
+  refill N    añadir N raciones al cuenco
+  speed X     escalar la velocidad de pensar; 0.5 piensa el doble
+  faster      duplicar la velocidad
+  slower      reducir la velocidad a la mitad
+  step        desde una pausa, seguir hasta la próxima ración y detenerse
+  checkpoint  guardar la cena en el archivo -checkpoint y terminarla
+  help        mostrar esta lista`,
+		msgTryHelp:             "Pruebe \"help\".",
+		msgControlResuming:     "Reanudando.",
+		msgControlPausing:      "Deteniendo en el próximo punto seguro...",
+		msgControlStepped:      "Un paso; detenida de nuevo.",
+		msgNoCheckpointFile:    "No hay archivo -checkpoint donde guardar la cena.",
+		msgControlStopping:     "Terminando en el próximo punto seguro...",
+		msgNoPhilosopher:       "No hay filósofo %q.",
+		msgAlreadyAsked:        "Ya se pidió al filósofo %d que se fuera.",
+		msgBadServings:         "Número de raciones incorrecto %q.",
+		msgBowlEmpty:           "Demasiado tarde; el cuenco ya está vacío.",
+		msgAddedServings:       "Añadidas %d raciones.",
+		msgBadSpeed:            "Velocidad incorrecta %q.",
+		msgThinkingTakes:       "Pensar ahora lleva %v.",
+		msgKeyHelp:             "+ más rápido  - más lento  p pausa  s paso  v detalle  h el más hambriento  a todos",
+		msgTUITitle:            "Filósofos cenando - %d a la mesa",
+		msgTUILegend:           "E come  T piensa  W espera  x se fue   | palillo en bandeja  < > en manos del anterior/siguiente",
+		msgTUIRice:             "arroz: %d",
+		msgTUIElapsed:          "duración  %v",
+		msgTUIServings:         "raciones  %d comidas",
+		msgTUIEvents:           "eventos   %d (%.0f/s)",
+		msgTUIEating:           "comiendo  %d",
+		msgTUIThinking:         "pensando  %d",
+		msgTUIWaiting:          "esperando %d",
+		msgTUIGone:             "idos      %d",
+		msgTUIUnfed:            "sin comer %d",
+		msgTUIHungriest:        "más hambriento p%d (esperó %d, comió %d)",
+		msgTUIWatching:         "observando p%d (esperó %d, comió %d)",
+		msgTUIRecent:           "eventos recientes:",
+		msgAnimateStatus:       "arroz restante %d, comido %d, comiendo %d, esperando %d",
+		msgDoctorCPUs:          "cpus",
+		msgDoctorMaxProcsEnv:   "%s, aunque las cenas usan todas las cpus",
+		msgDoctorRace:          "detector race",
+		msgDoctorOn:            "sí",
+		msgDoctorOff:           "no",
+		msgDoctorMaxThreads:    "máx. hilos",
+		msgDoctorOpenFiles:     "archivos",
+		msgDoctorStack:         "pila",
+		msgDoctorAddressSpace:  "espacio dir.",
+		msgDoctorLimit:         "%s (máximo %s)",
+		msgDoctorUnlimited:     "ilimitado",
+		msgDoctorLimits:        "límites",
+		msgDoctorLimitsUnknown: "desconocidos en este sistema",
+		msgDoctorGovernor:      "gobernador",
+		msgDoctorTerminal:      "terminal",
+		msgDoctorNotTerminal:   "no es un terminal",
+		msgDoctorTerminalSize:  "%dx%d, color %s",
+		msgWarnOneCPU:          "solo una cpu: los filósofos se turnan en lugar de competir en paralelo",
+		msgWarnRace:            "el detector de carreras lo ralentiza todo varias veces; los tiempos y la contención quedarán distorsionados",
+		msgWarnAddressSpace:    "el espacio de direcciones está limitado a %d bytes; el runtime de Go podría no poder ampliar el heap",
+		msgWarnGovernor:        "el escalado de frecuencia de la cpu (gobernador %s) hace variar los tiempos entre ejecuciones",
+		msgWarnSmallTerminal:   "el terminal es menor de %dx%d; tui se verá recortado",
+		msgWarnNoColor:         "el terminal no muestra color; tui y -ids=%s pierden su sentido",
+		msgWarnOutputAll:       "mostrar cada evento en un terminal limita la cena a la velocidad del terminal",
+		msgDoctorNoWarnings:    "sin avisos",
+		msgDoctorWarnings:      "avisos:",
+		msgGenWrote:            "escritos %d escenarios con semilla %d",
+		msgExportWrote:         "escritos %d fotogramas de %d filósofos en %s",
+		msgMatrixRun:           "GOMAXPROCS %d, %d filósofos: %.0f raciones/s, %.1f esperas cada uno",
+		msgMatrixThroughput:    "Raciones comidas por segundo:",
+		msgMatrixWaits:         "Veces que cada filósofo tuvo que esperar:",
+		msgMatrixCorner:        "comensales\\procs",
+	},
+}
+
+// messages is the catalog tr formats from: English, unless chooseLanguage finds another.
+var messages = catalogs["en"]
+
+// chooseLanguage picks the catalog named by the -lang flag or, failing that,
+// the locale environment variables, which look like "de_DE.UTF-8".
+// It returns false if a language was asked for but has no catalog.
+func chooseLanguage() (string, bool) {
+	lang := *flagLang
+	explicit := lang != ""
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang != "" {
+			break
+		}
+		lang = os.Getenv(env)
+	}
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "_.@-"); i >= 0 {
+		lang = lang[:i]
+	}
+	if catalog, ok := catalogs[lang]; ok {
+		messages = catalog
+		return lang, true
+	}
+	return lang, !explicit
+}
+
+// tr formats a message in the chosen language.
+func tr(key msgKey, args ...any) string {
+	format, ok := messages[key]
+	if !ok {
+		format = catalogs["en"][key]
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Notification styles, selected with the -notify flag.
+const (
+	notifyNone    = "none"
+	notifyBell    = "bell"
+	notifyDesktop = "desktop"
+)
+
+var (
+	flagNotify = flag.String("notify", notifyNone,
+		"on the first starvation, a watchdog alarm and the end of the dinner: "+
+			notifyNone+", "+notifyBell+" or "+notifyDesktop)
+	flagWatchdog = flag.Duration("watchdog", time.Second,
+		"with -notify or -webhook, raise the alarm when philosophers keep trying but nobody eats for this long; 0 disables")
+)
+
+// notifyTitle heads desktop notifications.
+const notifyTitle = "Dining philosophers"
+
+func validNotifyStyle(style string) bool {
+	switch style {
+	case notifyNone, notifyBell, notifyDesktop:
+		return true
+	}
+	return false
+}
+
+// Alarms a notifier raises.
+const (
+	alarmStarved = "starved"
+	alarmStalled = "stalled"
+	alarmDone    = "done"
+	// alarmCheckpointed is raised instead of alarmDone when the dinner
+	// stops to be resumed from a checkpoint; it isn't over.
+	alarmCheckpointed = "checkpointed"
+)
+
+// notifier is a spectator that hands events on to the spectator it wraps,
+// and calls for attention when the first philosopher leaves without eating,
+// when the dinner stalls, and when it ends - for long dinners in a window
+// no one is looking at, or watched by other programs through a webhook.
+type notifier struct {
+	spectator
+	// style is how to get a person's attention, if at all.
+	style    string
+	watchdog time.Duration
+	// webhook, if not empty, is where to post a report with every alarm.
+	webhook string
+	dt      diningTable
+	view    *tableView
+	// pending are notifications still being delivered.
+	pending sync.WaitGroup
+	mu      sync.Mutex
+	// failures holds what went wrong posting to the webhook; guarded by mu.
+	failures []error
+}
+
+func newNotifier(sp spectator, dt diningTable, style string, watchdog time.Duration, webhook string) *notifier {
+	return &notifier{
+		spectator: sp,
+		style:     style,
+		watchdog:  watchdog,
+		webhook:   webhook,
+		dt:        dt,
+		view:      newTableView(len(dt)),
+	}
+}
+
+func (n *notifier) watch(events <-chan event) {
+	starved, stalled := false, false
+	lastMeal, lastEvent := time.Now(), time.Now()
+	passOn(n.spectator, events, func(e event) {
+		n.view.apply(e)
+		// A dinner that's been quiet, say because it was paused,
+		// gets a fresh start from the watchdog.
+		if e.at.Sub(lastEvent) > n.watchdog {
+			lastMeal = e.at
+		}
+		lastEvent = e.at
+		switch e.act {
+		case actEat:
+			lastMeal, stalled = e.at, false
+		case actLeave:
+			if !starved && n.view.eaten[e.id] == 0 {
+				starved = true
+				n.alarmLater(alarmStarved, tr(msgNotifyStarved, e.id))
+			}
+		}
+		// Only philosophers still trying make events, so quiet ones,
+		// paused or thinking, are never taken to be stalled.
+		if n.watchdog > 0 && !stalled && e.at.Sub(lastMeal) > n.watchdog {
+			stalled = true
+			n.alarmLater(alarmStalled, tr(msgNotifyStalled, n.watchdog))
+		}
+	})
+	if n.view.count(stateGone) < len(n.dt) {
+		// Stopped for a checkpoint, so some are still at the table.
+		n.alarm(alarmCheckpointed, tr(msgNotifyCheckpointed), n.view.interimReport())
+	} else {
+		// Everyone has left, so the table can be read.
+		n.alarm(alarmDone, tr(msgNotifyDone), n.dt.finalReport())
+	}
+	n.pending.Wait()
+	for _, err := range n.failures {
+		fmt.Println(err)
+	}
+}
+
+// alarmLater raises an alarm without holding up the dinner.
+// The report is made right away, while the view is still this goroutine's.
+func (n *notifier) alarmLater(kind, msg string) {
+	report := n.view.interimReport()
+	n.pending.Add(1)
+	go func() {
+		defer n.pending.Done()
+		n.alarm(kind, msg, report)
+	}()
+}
+
+// alarm rings the terminal bell or, if asked for and possible, shows
+// a desktop notification, and posts to the webhook.
+func (n *notifier) alarm(kind, msg string, report jsonReport) {
+	switch n.style {
+	case notifyDesktop:
+		if desktopNotify(notifyTitle, msg) == nil {
+			break
+		}
+		fallthrough
+	case notifyBell:
+		fmt.Print("\a")
+	}
+	if n.webhook == "" {
+		return
+	}
+	if err := postWebhook(n.webhook, kind, msg, report); err != nil {
+		n.mu.Lock()
+		n.failures = append(n.failures, err)
+		n.mu.Unlock()
+	}
+}
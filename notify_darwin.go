@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// desktopNotify shows a notification through the Notification Center.
+func desktopNotify(title, msg string) error {
+	script := fmt.Sprintf("display notification %q with title %q", msg, title)
+	return exec.Command("osascript", "-e", script).Run()
+}
@@ -0,0 +1,8 @@
+package main
+
+import "os/exec"
+
+// desktopNotify shows a notification through the desktop's notification daemon.
+func desktopNotify(title, msg string) error {
+	return exec.Command("notify-send", title, msg).Run()
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin)
+
+package main
+
+import "errors"
+
+// desktopNotify has no desktop to notify here; callers ring the bell instead.
+func desktopNotify(title, msg string) error {
+	return errors.New("no desktop notifications on this system")
+}
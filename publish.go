@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// publishTokenEnv names the environment variable holding the token for
+// -publish, when -publishToken isn't given; it keeps the token out of
+// process listings and shell history.
+const publishTokenEnv = "PHILO_PUBLISH_TOKEN"
+
+var (
+	flagPublish = flag.String("publish", "",
+		"URL of a collector to push the final report to, at /reports, and any -eventlog to, at /eventlogs")
+	flagPublishToken = flag.String("publishToken", "",
+		"bearer token for -publish; defaults to $"+publishTokenEnv)
+	flagPublishRetries = flag.Int("publishRetries", 3,
+		"times to retry a push to -publish that fails for a reason that might pass")
+)
+
+// publishBackoff is how long to wait before the first retry;
+// each retry after that waits twice as long as the one before.
+const publishBackoff = time.Second
+
+// publisher pushes the results of a dinner to a collector shared by many
+// machines. Everything pushed from one dinner carries the same run id,
+// so the collector can put a report together with its event log.
+type publisher struct {
+	url     string
+	token   string
+	retries int
+	runID   string
+	client  *http.Client
+}
+
+func newPublisher(url string) *publisher {
+	token := *flagPublishToken
+	if token == "" {
+		token = os.Getenv(publishTokenEnv)
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &publisher{
+		url:     strings.TrimRight(url, "/"),
+		token:   token,
+		retries: *flagPublishRetries,
+		runID:   fmt.Sprintf("%s-%d", host, time.Now().UnixNano()),
+		client:  &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// publishReport pushes a report.
+func (p *publisher) publishReport(r jsonReport) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return p.push("/reports", "application/json", func() (io.ReadCloser, int64, error) {
+		return io.NopCloser(bytes.NewReader(body)), int64(len(body)), nil
+	})
+}
+
+// publishEventLog pushes the event log in the named file.
+func (p *publisher) publishEventLog(name string) error {
+	return p.push("/eventlogs", "application/x-ndjson", func() (io.ReadCloser, int64, error) {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, 0, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		return f, info.Size(), nil
+	})
+}
+
+// push posts what body returns, a reader and its size, to path under the
+// collector's url, trying again after a pause if the collector can't be
+// reached or says it's busy or broken. Other refusals, like a bad token,
+// won't pass by retrying.
+func (p *publisher) push(path, contentType string, body func() (io.ReadCloser, int64, error)) error {
+	backoff := publishBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := p.post(p.url+path, contentType, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= p.retries {
+			return fmt.Errorf("publish: %v", err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post makes one attempt at a push, saying whether a failure is worth retrying.
+func (p *publisher) post(url, contentType string, body func() (io.ReadCloser, int64, error)) (retry bool, err error) {
+	b, size, err := body()
+	if err != nil {
+		return false, err
+	}
+	defer b.Close()
+	req, err := http.NewRequest(http.MethodPost, url, b)
+	if err != nil {
+		return false, err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Run-Id", p.runID)
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode/100 == 2:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode/100 == 5:
+		return true, fmt.Errorf("%s answered %s", url, resp.Status)
+	}
+	return false, fmt.Errorf("%s answered %s", url, resp.Status)
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package main
+
+// raceEnabled is true when built with -race, which slows the dinner a lot.
+const raceEnabled = true
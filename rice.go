@@ -20,16 +20,62 @@
   (NumPhilosophers, ThingingDuration, NumServings, etc).
 
   - Every philosopher is a go routine.
+  - How a philosopher gets both sticks is a strategy, chosen with
+    -strategy from those built in or loaded from a Go plugin. The fair
+    strategy bounds how long anyone waits, and the report checks it did.
   - The rice bowl is a channel of servings.
   - The chopsticks are objects that can collect stats about their use.
   - The trays are channels to hand sticks back and forth.
+  - Philosophers report what they do on a channel of events, drained by
+    a printer that coalesces them when they arrive faster than a terminal
+    can show them (see the -output flag).
+  - Sending SIGUSR1 pauses everyone at their next safe point and prints
+    a snapshot; a second SIGUSR1 resumes the dinner.
+  - With -interactive, commands typed on standard input drive the
+    dinner while it runs (type "help" for a list).
+  - The tui command shows the table as a live, full-screen dashboard
+    instead of printing events; the animate command does the same with
+    a compact picture redrawn in place, for plain terminals. Both take
+    keys to change speed, pause, step, and choose what to show.
+  - The teach command steps through a small dinner an event at a time,
+    on a key press, explaining what each step means for the sticks and
+    pointing out waits that could become circular.
+  - Events and reports come from a message catalog, in the language
+    chosen with -lang or by the locale.
+  - The matrix command reruns a dinner across GOMAXPROCS values and
+    table sizes, and shows how throughput and waiting scale.
+  - With -eventlog, every event is recorded to a file, which the export
+    command turns into an animated SVG or GIF of the dinner.
+  - With -timeline, or the timeline command given an event log, each
+    philosopher's eating, thinking and waiting is drawn as a lane of
+    characters on a shared time axis, to show convoys and starvation.
+  - With -notify, the terminal bell or a desktop notification calls for
+    attention when someone first leaves hungry, when a watchdog finds
+    the dinner stalled, and when it ends or stops at a checkpoint; with
+    -webhook, a JSON report is posted to a URL at the same moments.
+  - With -publish, the final report and any event log are pushed to a
+    collector, so a fleet of machines can gather their results in one place.
+  - The coordinate and dine commands split a dinner across processes:
+    the table in one, philosophers in others, reaching for sticks and
+    rice over the network.
+  - With -record, a dinner's settings and report are kept in a history
+    file, for the history command to list, show and compare.
+  - With -checkpoint, SIGTERM stops the dinner at a safe point and saves
+    it, and -resume carries on from where it stopped.
+  - With -scenario, a JSON file describes the dinner instead of the
+    constants: groups of philosophers with their own thinking times and
+    strategies, the servings, and commands to send as the dinner goes on.
+    The gen command writes random ones, within bounds, to explore with.
 */
 
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 )
@@ -60,6 +106,10 @@ type chopStick struct {
 	id        int
 	countGrab int
 	countEat  int
+	// releasedAt is when the stick was last put into its tray.
+	// Written by the releasing philosopher before the channel send,
+	// so the receiving philosopher can safely read it.
+	releasedAt time.Time
 }
 
 type riceBowl chan serving
@@ -80,6 +130,11 @@ type philosopher struct {
 	id                 int
 	hadToWaitCount     int
 	servingsEatenCount int
+	// Scheduler wakeup latency: the gap between a stick landing in a tray
+	// and this philosopher, already blocked waiting on that tray, receiving it.
+	wakeupCount      int
+	wakeupLatencySum time.Duration
+	wakeupLatencyMax time.Duration
 	// the philosopher's hands can hold a chopstick (or nil)
 	handLeft  *chopStick
 	handRight *chopStick
@@ -87,17 +142,52 @@ type philosopher struct {
 	// These are never nil once initialized.
 	trayLeft  *stickTray
 	trayRight *stickTray
+	// events is where the philosopher reports what it's doing.
+	events chan<- event
+	// kill is closed to ask the philosopher to leave at its next safe point.
+	kill chan struct{}
+	// grab is how the philosopher gets its sticks.
+	grab strategy
+	// thinkingScale stretches or shrinks the time spent thinking.
+	thinkingScale float64
+	// longestWait is the longest it took to get both sticks, once hungry;
+	// waitBound is the longest its strategy promised, or zero.
+	longestWait time.Duration
+	waitBound   time.Duration
 }
 
 func (p *philosopher) dump() {
-	fmt.Printf("philosopher%3d waited%4d times, ate%4d times  ",
-		p.id, p.hadToWaitCount, p.servingsEatenCount)
+	fmt.Print(tr(msgPhilosopherStats,
+		p.id, p.hadToWaitCount, p.servingsEatenCount,
+		p.wakeupCount, p.avgWakeupLatency(), p.wakeupLatencyMax))
 	if p.servingsEatenCount == 0 {
-		fmt.Printf(" STARVED!")
+		fmt.Print(tr(msgStarved))
 	}
 	fmt.Println()
 }
 
+func (p *philosopher) avgWakeupLatency() time.Duration {
+	if p.wakeupCount == 0 {
+		return 0
+	}
+	return p.wakeupLatencySum / time.Duration(p.wakeupCount)
+}
+
+// noteWakeup records scheduler latency for a stick received after blocking.
+// If the stick was already in the tray when the philosopher began waiting,
+// there was no wakeup to measure, so nothing is recorded.
+func (p *philosopher) noteWakeup(stick *chopStick, waitStart time.Time) {
+	if !stick.releasedAt.After(waitStart) {
+		return
+	}
+	latency := time.Since(stick.releasedAt)
+	p.wakeupCount++
+	p.wakeupLatencySum += latency
+	if latency > p.wakeupLatencyMax {
+		p.wakeupLatencyMax = latency
+	}
+}
+
 // seat groups a philosopher, an actual chopStick, and a tray to put the stick in
 // when it's not being used to eat.  The trouble is that each philosopher must
 // share their stick with their neighbor.
@@ -111,89 +201,161 @@ type seat struct {
 // diningTable arranges N seats in a ring.
 type diningTable []seat
 
-// Indented philosopher id, to facilitate reading interleaved go routine output.
-func (p *philosopher) sid() string {
-	return fmt.Sprintf("%*sp%d", 2*(p.id+1), " ", p.id)
+// emit hands an event, described by a message from the catalog, to the spectator.
+func (p *philosopher) emit(act action, stick, tries int, key msgKey, args ...any) {
+	p.events <- event{
+		at:    time.Now(),
+		id:    p.id,
+		act:   act,
+		stick: stick,
+		tries: tries,
+		msg:   tr(key, args...),
+	}
 }
 
 func (p *philosopher) eat() {
 	p.handLeft.countEat++
 	p.handRight.countEat++
 	p.servingsEatenCount++
-	fmt.Printf("%s eats!\n", p.sid())
+	p.emit(actEat, -1, 0, msgEats)
 }
 
-func (p *philosopher) think() {
-	fmt.Printf("%s has eaten %d bites; starting to think.\n", p.sid(), p.servingsEatenCount)
-	time.Sleep(ThinkingDuration)
-	fmt.Printf("%s done thinking.\n", p.sid())
+func (p *philosopher) think(d time.Duration) {
+	p.emit(actThink, -1, 0, msgStartsThinking, p.servingsEatenCount)
+	time.Sleep(d)
+	p.emit(actDoneThinking, -1, 0, msgDoneThinking)
 }
 
-func (p *philosopher) releaseLeft(why string) {
-	fmt.Printf("%s releases stick %d; %s.\n", p.sid(), p.handLeft.id, why)
+func (p *philosopher) releaseLeft(why msgKey) {
+	p.emit(actRelease, p.handLeft.id, 0, msgReleases, p.handLeft.id, tr(why))
+	p.handLeft.releasedAt = time.Now()
 	p.trayLeft.ch <- p.handLeft
 	p.handLeft = nil
 }
 
-func (p *philosopher) releaseRight(why string) {
-	fmt.Printf("%s releases stick %d; %s.\n", p.sid(), p.handRight.id, why)
+func (p *philosopher) releaseRight(why msgKey) {
+	p.emit(actRelease, p.handRight.id, 0, msgReleases, p.handRight.id, tr(why))
+	p.handRight.releasedAt = time.Now()
 	p.trayRight.ch <- p.handRight
 	p.handRight = nil
 }
 
-// grabSticks grabs two sticks - the one from the left tray and the one from the right tray
+// grabSticks grabs two sticks - the one from the left tray and the one from
+// the right tray - in the way the chosen strategy says.
 func (p *philosopher) grabSticks() {
+	hungry := time.Now()
 	tries := 0
-	for {
+	hand := func(side int) (**chopStick, *stickTray) {
+		if side == sideLeft {
+			return &p.handLeft, p.trayLeft
+		}
+		return &p.handRight, p.trayRight
+	}
+	took := func(side int, stick *chopStick) {
+		h, _ := hand(side)
+		*h = stick
+		stick.countGrab++
+		act, key, keyBoth := actTakeLeft, msgTakesLeft, msgTakesLeftHasBoth
+		if side == sideRight {
+			act, key, keyBoth = actTakeRight, msgTakesRight, msgTakesRightHasBoth
+		}
+		if p.handLeft != nil && p.handRight != nil {
+			p.emit(act, stick.id, tries, keyBoth, stick.id, tries)
+			return
+		}
+		p.emit(act, stick.id, tries, key, stick.id)
+	}
+	takeEither := func() int {
+		waitStart := time.Now()
 		select {
-		case p.handLeft = <-p.trayLeft.ch:
-			p.handLeft.countGrab++
-			fmt.Printf("%s takes stick %d from left.\n", p.sid(), p.handLeft.id)
-			select {
-			case p.handRight = <-p.trayRight.ch:
-				p.handRight.countGrab++
-				fmt.Printf("%s takes stick %d from right; now has both (%d tries).\n", p.sid(), p.handRight.id, tries)
-				return
-			default:
-				p.releaseLeft("got left, but unable to get right")
-			}
-
-		case p.handRight = <-p.trayRight.ch:
-			p.handRight.countGrab++
-			fmt.Printf("%s takes stick %d from right.\n", p.sid(), p.handRight.id)
-			select {
-			case p.handLeft = <-p.trayLeft.ch:
-				p.handLeft.countGrab++
-				fmt.Printf("%s takes stick %d from left; now has both (%d tries).\n", p.sid(), p.handLeft.id, tries)
-				return
-			default:
-				p.releaseRight("got right, but unable to get left")
-			}
+		case stick := <-p.trayLeft.ch:
+			p.noteWakeup(stick, waitStart)
+			took(sideLeft, stick)
+			return sideLeft
+		case stick := <-p.trayRight.ch:
+			p.noteWakeup(stick, waitStart)
+			took(sideRight, stick)
+			return sideRight
+		}
+	}
+	take := func(side int, wait bool) bool {
+		_, tray := hand(side)
+		if wait {
+			waitStart := time.Now()
+			stick := <-tray.ch
+			p.noteWakeup(stick, waitStart)
+			took(side, stick)
+			return true
+		}
+		select {
+		case stick := <-tray.ch:
+			took(side, stick)
+			return true
+		default:
+			return false
 		}
+	}
+	put := func(side int) {
+		if side == sideLeft {
+			p.releaseLeft(msgWhyNoRight)
+			return
+		}
+		p.releaseRight(msgWhyNoLeft)
+	}
+	waited := func() {
 		p.hadToWaitCount++
 		tries++
-		fmt.Printf("%s unable to get chopsticks in %d consecutive attempts.\n", p.sid(), tries)
+		p.emit(actWait, -1, tries, msgCantGetSticks, tries)
+	}
+	// A tray's stick has the id of the philosopher to the tray's left.
+	p.grab(p.trayLeft.left.id, p.trayRight.left.id, takeEither, take, put, waited)
+	if w := time.Since(hungry); w > p.longestWait {
+		p.longestWait = w
 	}
 }
 
-func (p *philosopher) releaseSticks(msg string) {
-	p.releaseLeft(msg)
-	p.releaseRight(msg)
+func (p *philosopher) releaseSticks(why msgKey) {
+	p.releaseLeft(why)
+	p.releaseRight(why)
 }
 
-func (p *philosopher) eatAndThink(bowl riceBowl, wait *sync.WaitGroup) {
+func (p *philosopher) eatAndThink(bowl riceBowl, ctl *controller, wait *sync.WaitGroup) {
 	for {
+		select {
+		case <-p.kill:
+			p.emit(actLeave, -1, 0, msgAskedToLeave)
+			wait.Done()
+			return
+		case <-ctl.stopped:
+			// Saved in a checkpoint, to come back later.
+			wait.Done()
+			return
+		default:
+		}
+		ctl.enter()
+		select {
+		case <-ctl.stopped:
+			// The checkpoint was taken while waiting to enter.
+			ctl.leave()
+			wait.Done()
+			return
+		default:
+		}
 		p.grabSticks()
 		// Take a serving
 		if _, ok := <-bowl; !ok {
 			// No more food, time to leave.
-			p.releaseSticks("no more food")
+			p.releaseSticks(msgWhyNoFood)
+			p.emit(actLeave, -1, 0, msgLeaves)
+			ctl.leave()
 			wait.Done()
 			return
 		}
 		p.eat()
-		p.releaseSticks("ate one serving")
-		p.think()
+		p.releaseSticks(msgWhyAte)
+		thinking := time.Duration(float64(ctl.thinking) * p.thinkingScale)
+		ctl.leave()
+		p.think(thinking)
 	}
 }
 
@@ -203,6 +365,10 @@ func makeDiningTable(numPhilosophers int) diningTable {
 	// Make everything.
 	for i := range tuples {
 		tuples[i].diner.id = i
+		tuples[i].diner.kill = make(chan struct{})
+		tuples[i].diner.grab = grab
+		tuples[i].diner.waitBound = waitBound
+		tuples[i].diner.thinkingScale = 1
 		// Using no buffer below would mean that a chopstick could not be put down until someone was waiting
 		// to pick it up.  That would be a problem, since philosophers sometimes think rather than try to eat.
 		// Using a buffer of size '1' here means it's possible to put a chopstick down if nobody is waiting.
@@ -228,80 +394,466 @@ func makeDiningTable(numPhilosophers int) diningTable {
 	return tuples
 }
 
-func (dt diningTable) report() {
-	fmt.Println("\nReport:")
+func (dt diningTable) report(title string) {
+	fmt.Println("\n" + title)
+	printBuildInfo()
 	for i := range dt {
 		dt[i].diner.dump()
 	}
+	dt.reportWakeupLatency()
+	dt.reportLongestWait()
+	dt.reportWaitBound()
 	for i := range dt {
-		fmt.Printf("stick%3d grabbed%4d times, used to eat%4d times\n",
-			i, dt[i].stick.countGrab, dt[i].stick.countEat)
+		fmt.Println(tr(msgStickStats, i, dt[i].stick.countGrab, dt[i].stick.countEat))
 	}
 }
 
-func (dt diningTable) placeChopsticksInTrays() {
+// reportWakeupLatency summarizes, across all philosophers, how long the Go
+// scheduler took to hand a newly released stick to a philosopher blocked on its tray.
+func (dt diningTable) reportWakeupLatency() {
+	count := 0
+	var sum, longest time.Duration
 	for i := range dt {
-		fmt.Printf("Placing chopstick %d\n", i)
+		p := &dt[i].diner
+		count += p.wakeupCount
+		sum += p.wakeupLatencySum
+		if p.wakeupLatencyMax > longest {
+			longest = p.wakeupLatencyMax
+		}
+	}
+	if count == 0 {
+		fmt.Println(tr(msgNoWakeups))
+		return
+	}
+	fmt.Println(tr(msgWakeups, count, sum/time.Duration(count), longest))
+}
+
+// reportLongestWait says who waited longest for sticks, once hungry.
+func (dt diningTable) reportLongestWait() {
+	longest := 0
+	for i := range dt {
+		if dt[i].diner.longestWait > dt[longest].diner.longestWait {
+			longest = i
+		}
+	}
+	fmt.Println(tr(msgLongestWait, dt[longest].diner.longestWait.Round(time.Microsecond), longest))
+}
+
+func (dt diningTable) placeChopsticksInTrays(verbose bool) {
+	for i := range dt {
+		if verbose {
+			fmt.Println(tr(msgPlacingStick, i))
+		}
+		dt[i].stick.releasedAt = time.Now()
 		dt[i].tray.ch <- &dt[i].stick
 	}
 }
 
-// serveDinner makes a table, starts everyone eating, and waits till they are all done.
-func (dt diningTable) serveDinner(numServings int) {
+// eventBuffer lets philosophers run ahead of the spectator in bursts.
+// The teach command sets it to zero, to hold them in step with what's shown.
+var eventBuffer = 1024
+
+// serveDinner starts everyone eating, thinking at the given speed, shows
+// what happens to the spectator, and waits till they are all done.
+// Commands for the controller, if any, are read from the commands channel,
+// and those of a scenario's phases from script, which may be nil.
+// If the dinner was stopped to be resumed later, its checkpoint is returned.
+func (dt diningTable) serveDinner(ch *chef, sp spectator, commands chan string, script <-chan string,
+	speed float64) *checkpoint {
+	events := make(chan event, eventBuffer)
+	watched := make(chan struct{})
+	go func() {
+		sp.watch(events)
+		close(watched)
+	}()
 	// Start philosophers - though they won't be able to eat till the sticks are out and rice is served.
 	// Each philosopher expected to eat from the bowl until
 	// the bowl is empty, then signal their completion on the WaitGroup.
-	bowl := make(riceBowl, numServings)
+	ctl := newController(dt, ch, sp, speed)
 	var wait sync.WaitGroup
-	wait.Add(NumPhilosophers)
+	wait.Add(len(dt))
 	for i := range dt {
-		go dt[i].diner.eatAndThink(bowl, &wait)
+		dt[i].diner.events = events
+		go dt[i].diner.eatAndThink(ch.bowl, ctl, &wait)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go watchSignals(commands, done, *flagCheckpoint != "")
+	go ctl.run(commands, script, done)
+
+	// Only say what's going on if the spectator doesn't own the terminal.
+	verbose := !sp.ownsTerminal()
+	if verbose {
+		fmt.Println(tr(msgGoroutinesStarted, runtime.NumGoroutine()))
 	}
-
-	fmt.Printf("Philosophers started, numGoroutine = %d\n", runtime.NumGoroutine())
 
 	// Unblock everyone, but there's still nothing to eat.
-	dt.placeChopsticksInTrays()
+	dt.placeChopsticksInTrays(verbose)
 	// Now serve the rice.
-	go serveRice(bowl, numServings)
+	go ch.serveRice()
 	// Wait for everyone to finish eating all the servings.
 	wait.Wait()
+	close(events)
+	<-watched
+	return ctl.checkpointed()
+}
 
-	dt.report()
+// chef hands out servings through the bowl, one at a time,
+// and takes refill orders until the last serving is gone.
+type chef struct {
+	numServings int
+	bowl        riceBowl
+	// refill adds servings to those not yet served.
+	refill chan int
+	// left reports how many servings have not yet been served.
+	left chan int
+	// emptied is closed along with the bowl.
+	emptied chan struct{}
+	// watchers are closed when the next serving is taken.
+	watchers chan chan struct{}
+}
+
+func newChef(numServings int) *chef {
+	return &chef{
+		numServings: numServings,
+		// Unbuffered, so servings not yet handed out can still be counted (and refilled).
+		bowl:     make(riceBowl),
+		refill:   make(chan int),
+		left:     make(chan int),
+		emptied:  make(chan struct{}),
+		watchers: make(chan chan struct{}),
+	}
 }
 
-// serveRice just fills a channel with servings and closes it.
+// serveRice feeds the bowl with servings and closes it once they're all eaten.
 // The channel assures only one diner can eat a serving.
 // Allows accurate total consumption count.
 // Since this is just a counter decrement, could model it as a semaphore protected int,
 // but goal here is to use only channels for synchronization.
-func serveRice(ch riceBowl, numServings int) {
-	for i := 0; i < numServings; i++ {
-		ch <- serving{}
+func (c *chef) serveRice() {
+	var watchers []chan struct{}
+	for remaining := c.numServings; remaining > 0; {
+		select {
+		case c.bowl <- serving{}:
+			remaining--
+			for _, w := range watchers {
+				close(w)
+			}
+			watchers = nil
+		case n := <-c.refill:
+			remaining += n
+		case c.left <- remaining:
+		case w := <-c.watchers:
+			watchers = append(watchers, w)
+		}
+	}
+	close(c.bowl)
+	close(c.emptied)
+}
+
+// nextServing returns a channel closed when the next serving is taken,
+// or never, if the bowl has already been emptied.
+func (c *chef) nextServing() <-chan struct{} {
+	w := make(chan struct{})
+	select {
+	case c.watchers <- w:
+	case <-c.emptied:
+	}
+	return w
+}
+
+// servingsLeft returns the number of servings not yet eaten.
+func (c *chef) servingsLeft() int {
+	select {
+	case n := <-c.left:
+		return n
+	case <-c.emptied:
+		return 0
+	}
+}
+
+// addServings returns false if the bowl was already emptied.
+func (c *chef) addServings(n int) bool {
+	select {
+	case c.refill <- n:
+		return true
+	case <-c.emptied:
+		return false
 	}
-	close(ch)
 }
 
 func grabAllCpus() {
 	numCpus := runtime.NumCPU()
-	fmt.Printf("num cpus = %d\n", numCpus)
+	fmt.Println(tr(msgNumCpus, numCpus))
 	runtime.GOMAXPROCS(numCpus)
-	fmt.Printf("max cpus = %d\n", runtime.GOMAXPROCS(numCpus))
-	fmt.Printf("Before any 'go' starts, numGoroutine = %d\n", runtime.NumGoroutine())
+	fmt.Println(tr(msgMaxCpus, runtime.GOMAXPROCS(numCpus)))
+	fmt.Println(tr(msgGoroutinesBefore, runtime.NumGoroutine()))
+}
+
+// subcommands are named by the first argument, if it isn't a flag.
+// All of them accept the same flags.
+var subcommands = []struct{ name, help string }{
+	{"run", "print events as the dinner runs (the default)"},
+	{"tui", "show the dinner in a full-screen, live dashboard"},
+	{"animate", "redraw a compact picture of the table in place"},
+	{"teach", "step through a small dinner an event at a time, explaining each"},
+	{"completion", "print a completion script for bash, zsh or fish"},
+	{"version", "print the module version, VCS revision, Go version and build tags"},
+	{"doctor", "check the runtime environment for things that skew results"},
+	{"matrix", "rerun a dinner across GOMAXPROCS values and table sizes, and compare"},
+	{"export", "turn an -eventlog into an animated .svg or .gif"},
+	{"timeline", "draw what each philosopher did over time, from an -eventlog"},
+	{"coordinate", "serve the table of a distributed dinner to philosophers over the network"},
+	{"dine", "run philosophers at a distributed dinner's table, given by -coordinator"},
+	{"history", "list, show or compare dinners kept with -record"},
+	{"gen", "write random scenarios within bounds, to files named or standard output"},
+}
+
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "usage: %s [command] [flags] [args]\n\ncommands:\n", os.Args[0])
+	for _, c := range subcommands {
+		fmt.Fprintf(w, "  %-12s%s\n", c.name, c.help)
+	}
+	fmt.Fprintf(w, "\nflags:\n")
+	flag.PrintDefaults()
+}
+
+// exitOn ends a command that failed, with its error on standard error,
+// so it isn't mistaken for the command's output.
+func exitOn(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
 
 func main() {
-	fmt.Printf("version = %s\n", runtime.Version())
-	if NumPhilosophers < 2 {
-		fmt.Printf("Indexing scheme demands at least 2 philosophers.\n")
+	cmd, args := "run", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+	flag.Usage = usage
+	flag.CommandLine.Parse(args)
+	if lang, ok := chooseLanguage(); !ok {
+		fmt.Println(tr(msgUnknownLanguage, lang))
+	}
+	switch cmd {
+	case "run", "tui", "animate", "teach":
+	case "completion":
+		exitOn(writeCompletion(os.Stdout, flag.Arg(0)))
+		return
+	case "version":
+		printBuildInfo()
+		return
+	case "doctor":
+		doctor()
+		return
+	case "matrix":
+		exitOn(runMatrix())
+		return
+	case "export":
+		exitOn(export(flag.Args()))
+		return
+	case "timeline":
+		exitOn(drawTimeline(flag.Args()))
+		return
+	case "history":
+		exitOn(history(flag.Args()))
+		return
+	case "gen":
+		exitOn(gen(flag.Args()))
+		return
+	case "coordinate":
+		exitOn(coordinate())
+		return
+	case "dine":
+		exitOn(dine())
+		return
+	default:
+		// As for a bad flag, the usage follows, and the exit status is 2.
+		fmt.Fprintln(os.Stderr, tr(msgUnknownCommand, cmd))
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !validOutputPolicy(*flagOutput) {
+		fmt.Println(tr(msgUnknownPolicy, *flagOutput))
+		return
+	}
+	if err := chooseStrategy(*flagStrategy); err != nil {
+		fmt.Println(err)
+		return
+	}
+	var sc *scenario
+	numPhilosophers, servings := NumPhilosophers, NumServings
+	if *flagScenario != "" {
+		var err error
+		if sc, err = loadScenario(*flagScenario); err != nil {
+			fmt.Println(err)
+			return
+		}
+		numPhilosophers, servings = sc.philosophers(), sc.Servings
+	} else if cmd == "teach" {
+		numPhilosophers, servings = teachPhilosophers, teachServings
+	}
+	if !chooseIDStyle(*flagIDs, *flagLanes, numPhilosophers) {
+		fmt.Println(tr(msgUnknownIDStyle, *flagIDs))
+		return
+	}
+	if !validNotifyStyle(*flagNotify) {
+		fmt.Println(tr(msgUnknownNotify, *flagNotify))
+		return
+	}
+	tmpl, err := parseEventTemplate()
+	if err != nil {
+		fmt.Println(tr(msgBadTemplate, err))
+		return
+	}
+	fmt.Println(tr(msgVersion, runtime.Version()))
+	if sc != nil {
+		fmt.Println(tr(msgScenario, sc.Name, numPhilosophers, len(sc.Groups), len(sc.Phases)))
+	}
+	if numPhilosophers < 2 {
+		fmt.Println(tr(msgTooFew))
 		return
 	}
-	// Someone might starve even if NumServings > NumPhilosophers.
-	if NumServings < NumPhilosophers {
-		fmt.Printf("Starvation certain.\n")
+	// Someone might starve even if servings > numPhilosophers.
+	if servings < numPhilosophers {
+		fmt.Println(tr(msgStarvationCertain))
+	}
+	var resumed *checkpoint
+	left, speed := servings, 1.0
+	if *flagResume != "" {
+		if resumed, err = loadCheckpoint(*flagResume); err != nil {
+			fmt.Println(err)
+			return
+		}
+		left, speed = resumed.ServingsLeft, resumed.Speed
+		fmt.Println(tr(msgResuming, *flagResume, left))
 	}
 	grabAllCpus()
-	table := makeDiningTable(NumPhilosophers)
-	table.serveDinner(NumServings)
-	fmt.Printf("All done.\n")
+	commands := make(chan string)
+	ch := newChef(left)
+	var sp spectator
+	switch cmd {
+	case "tui", "animate":
+		// Keys drive these, so standard input can't also be read for commands.
+		keys, restore := useKeyboard()
+		defer restore()
+		if cmd == "tui" {
+			sp = newTUI(numPhilosophers, ch, frameInterval(), newKeyboard(keys, commands, true))
+		} else {
+			sp = newAnimation(numPhilosophers, ch, frameInterval(), newKeyboard(keys, commands, false))
+		}
+	case "teach":
+		keys, restore := useKeyboard()
+		defer restore()
+		eventBuffer = 0
+		sp = newTeacher(numPhilosophers, keys)
+	default:
+		if *flagInteractive {
+			go readCommands(os.Stdin, commands)
+		}
+		sp = newPrinter(numPhilosophers, *flagOutput, *flagMaxLineRate, tmpl)
+	}
+	table := makeDiningTable(numPhilosophers)
+	if sc != nil {
+		if err := sc.seat(table); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if resumed != nil {
+		if err := table.restore(resumed); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if *flagNotify != notifyNone || *flagWebhook != "" {
+		sp = newNotifier(sp, table, *flagNotify, *flagWatchdog, *flagWebhook)
+	}
+	var tl *timeline
+	if *flagTimeline {
+		tl = newTimeline(sp, numPhilosophers)
+		sp = tl
+	}
+	var rec *recorder
+	if *flagEventLog != "" {
+		// A resumed dinner carries on with the log it began.
+		if rec, err = newRecorder(sp, *flagEventLog, resumed != nil, numPhilosophers); err != nil {
+			fmt.Println(err)
+			return
+		}
+		sp = rec
+	}
+	var script chan string
+	if sc != nil {
+		script = make(chan string)
+		stop := make(chan struct{})
+		defer close(stop)
+		// A resumed dinner carries on with the phases it hadn't yet run.
+		var since time.Duration
+		var run int
+		if resumed != nil {
+			since, run = resumed.Elapsed, resumed.ScriptRun
+		}
+		go sc.play(script, stop, since, run)
+	}
+	start := time.Now()
+	saved := table.serveDinner(ch, sp, commands, script, speed)
+	elapsed := time.Since(start)
+	if resumed != nil {
+		elapsed += resumed.Elapsed
+	}
+	logged := rec != nil
+	if logged {
+		if err := rec.close(); err != nil {
+			fmt.Println(err)
+			logged = false
+		}
+	}
+	if saved != nil {
+		saved.Time = time.Now()
+		saved.Elapsed = elapsed
+		saved.Config = currentConfig(cmd, numPhilosophers, servings)
+		if resumed != nil {
+			saved.ScriptRun += resumed.ScriptRun
+		}
+		if err := saveCheckpoint(*flagCheckpoint, saved); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(tr(msgCheckpointSaved, *flagCheckpoint, *flagCheckpoint))
+		return
+	}
+	table.report(tr(msgReport))
+	if tl != nil {
+		fmt.Println()
+		tl.draw(os.Stdout, timelineWidth(numPhilosophers))
+	}
+	if *flagRecord {
+		id, err := recordHistory(*flagHistory, historyEntry{
+			Time:    start,
+			Elapsed: elapsed,
+			Config:  currentConfig(cmd, numPhilosophers, servings),
+			Report:  table.finalReport(),
+		})
+		if err != nil {
+			fmt.Println(err)
+		} else {
+			fmt.Println(tr(msgRecorded, id, *flagHistory))
+		}
+	}
+	if *flagPublish != "" {
+		pub := newPublisher(*flagPublish)
+		if err := pub.publishReport(table.finalReport()); err != nil {
+			fmt.Println(err)
+		}
+		if logged {
+			if err := pub.publishEventLog(*flagEventLog); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+	fmt.Println(tr(msgAllDone))
 }
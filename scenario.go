@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+var flagScenario = flag.String("scenario", "",
+	"JSON file describing the dinner: groups of philosophers, servings, and timed phases")
+
+// A scenario describes a dinner as data, for experiments that would
+// otherwise mean editing the constants. For example:
+//
+//	{
+//	  "name": "slow thinkers",
+//	  "servings": 2000,
+//	  "groups": [
+//	    {"name": "quick", "count": 8, "thinking": "1ms"},
+//	    {"name": "slow", "count": 2, "thinking": "20ms", "strategy": "ordered"}
+//	  ],
+//	  "phases": [
+//	    {"at": "200ms", "commands": ["kill p3"]},
+//	    {"at": "500ms", "commands": ["refill 500", "speed 0.5"]}
+//	  ]
+//	}
+//
+// Groups are seated around the table in order. Phases send controller
+// commands at a time after the dinner starts, which is how faults are
+// injected (kill, pause) and how the chef is directed (refill, speed).
+// There's one table, a ring, and one bowl with one chef.
+type scenario struct {
+	Name string `json:"name"`
+	// Topology is how the sticks are shared; only "ring" is modeled.
+	Topology string          `json:"topology,omitempty"`
+	Servings int             `json:"servings"`
+	Groups   []scenarioGroup `json:"groups"`
+	Phases   []scenarioPhase `json:"phases,omitempty"`
+}
+
+// scenarioGroup is a number of philosophers alike in how they dine.
+type scenarioGroup struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	// Thinking is how long they think between meals (default ThinkingDuration).
+	Thinking duration `json:"thinking,omitempty"`
+	// Strategy is how they get their sticks (default -strategy).
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// scenarioPhase is controller commands sent at a time into the dinner.
+type scenarioPhase struct {
+	At       duration `json:"at"`
+	Commands []string `json:"commands"`
+}
+
+// duration reads from JSON as a string like "1.5s".
+type duration time.Duration
+
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+// scenarioCommands are the controller commands a phase may send.
+var scenarioCommands = map[string]bool{
+	"pause": true, "resume": true, "toggle": true, "stats": true,
+	"watch": true, "kill": true, "refill": true,
+	"speed": true, "faster": true, "slower": true, "step": true,
+}
+
+func loadScenario(name string) (*scenario, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	sc := &scenario{}
+	if err := json.Unmarshal(b, sc); err != nil {
+		return nil, fmt.Errorf("reading scenario %s: %v", name, err)
+	}
+	sc.sortPhases()
+	if err := sc.validate(); err != nil {
+		return nil, fmt.Errorf("scenario %s: %v", name, err)
+	}
+	return sc, nil
+}
+
+// sortPhases puts the phases in the order they come.
+func (sc *scenario) sortPhases() {
+	sort.SliceStable(sc.Phases, func(i, j int) bool { return sc.Phases[i].At < sc.Phases[j].At })
+}
+
+func (sc *scenario) validate() error {
+	if sc.Topology != "" && sc.Topology != "ring" {
+		return fmt.Errorf("unknown topology %q; only \"ring\" is modeled", sc.Topology)
+	}
+	if sc.Servings <= 0 {
+		return errors.New("servings must be positive")
+	}
+	for i, g := range sc.Groups {
+		if g.Count < 1 {
+			return fmt.Errorf("group %d (%s) must have at least one philosopher", i+1, g.Name)
+		}
+		if g.Thinking < 0 {
+			return fmt.Errorf("group %d (%s) can't think for %v", i+1, g.Name, time.Duration(g.Thinking))
+		}
+		if g.Strategy != "" {
+			if _, err := lookupStrategy(g.Strategy); err != nil {
+				return fmt.Errorf("group %d (%s): %v", i+1, g.Name, err)
+			}
+		}
+	}
+	if sc.philosophers() < 2 {
+		return errors.New(tr(msgTooFew))
+	}
+	// Phases must be in order, to tell if the dinner is left paused.
+	paused := false
+	for i, ph := range sc.Phases {
+		if ph.At < 0 {
+			return fmt.Errorf("phase %d is at %v, before the dinner", i+1, time.Duration(ph.At))
+		}
+		for _, c := range ph.Commands {
+			fields := strings.Fields(c)
+			if len(fields) == 0 || !scenarioCommands[fields[0]] {
+				return fmt.Errorf("phase %d has unknown command %q", i+1, c)
+			}
+			switch fields[0] {
+			case "pause", "step":
+				paused = true
+			case "resume":
+				paused = false
+			case "toggle":
+				paused = !paused
+			}
+		}
+	}
+	if paused {
+		return errors.New("the last phase leaves the dinner paused, so it would never end; add a resume")
+	}
+	return nil
+}
+
+func (sc *scenario) philosophers() int {
+	n := 0
+	for _, g := range sc.Groups {
+		n += g.Count
+	}
+	return n
+}
+
+// seat gives everyone at the table the habits of their group.
+func (sc *scenario) seat(dt diningTable) error {
+	i := 0
+	for _, g := range sc.Groups {
+		s, bound := grab, waitBound
+		if g.Strategy != "" {
+			var err error
+			if s, err = lookupStrategy(g.Strategy); err != nil {
+				return err
+			}
+			bound = boundOf(g.Strategy)
+		}
+		for n := 0; n < g.Count; n, i = n+1, i+1 {
+			p := &dt[i].diner
+			p.grab, p.waitBound = s, bound
+			// Thinking is a scale of the controller's, so speed changes still apply.
+			if base := float64(ThinkingDuration); g.Thinking > 0 && base > 0 {
+				p.thinkingScale = float64(g.Thinking) / base
+			}
+		}
+	}
+	return nil
+}
+
+// play sends each phase's commands when its time comes, until stop is closed.
+// A resumed dinner has already run for a while, since, and already run
+// some of the commands, which are skipped.
+func (sc *scenario) play(commands chan<- string, stop <-chan struct{}, since time.Duration, run int) {
+	start := time.Now().Add(-since)
+	for _, ph := range sc.Phases {
+		if run >= len(ph.Commands) {
+			run -= len(ph.Commands)
+			continue
+		}
+		select {
+		case <-time.After(time.Until(start.Add(time.Duration(ph.At)))):
+		case <-stop:
+			return
+		}
+		cmds := ph.Commands[run:]
+		run = 0
+		for _, c := range cmds {
+			select {
+			case commands <- c:
+			case <-stop:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+// watchSignals does nothing where SIGUSR1 and SIGTERM can't be sent;
+// the checkpoint command still saves and stops a dinner.
+func watchSignals(_ chan<- string, done <-chan struct{}, _ bool) {
+	<-done
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchSignals asks the controller to toggle the pause state of the
+// dinner on every SIGUSR1 and, if checkpointing, to save and stop the
+// dinner on SIGTERM, until done is closed.
+func watchSignals(commands chan<- string, done <-chan struct{}, checkpointing bool) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGUSR1)
+	if checkpointing {
+		signal.Notify(sig, syscall.SIGTERM)
+	}
+	defer signal.Stop(sig)
+	for {
+		select {
+		case s := <-sig:
+			cmd := cmdToggle
+			if s == syscall.SIGTERM {
+				cmd = cmdCheckpoint
+			}
+			select {
+			case commands <- cmd:
+			case <-done:
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
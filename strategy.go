@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"plugin"
+	"sort"
+	"strings"
+)
+
+// Sides of a philosopher, as strategies name them.
+const (
+	sideLeft  = 0
+	sideRight = 1
+)
+
+// strategy is how a philosopher gets both sticks in hand. It's given the
+// ids of the sticks to its left and right, and hands to grab them with:
+//
+//   - takeEither waits for a stick to be in either tray, takes it,
+//     and returns its side;
+//   - take takes the stick on a side, waiting for it if wait is true,
+//     else returning false if it's not in its tray;
+//   - put puts the stick on a side back in its tray;
+//   - waited counts a failed attempt at getting both.
+//
+// It must return with both sticks in hand. Its type uses nothing but
+// builtin types, so a Go plugin can supply one without importing this
+// program: see loadStrategy.
+type strategy func(leftStick, rightStick int,
+	takeEither func() int, take func(side int, wait bool) bool, put func(side int), waited func())
+
+// strategyRetry is the original strategy: take whichever stick comes
+// first, then the other if it's there, else put the first back and retry.
+// Nobody ever waits holding a stick, so there's no deadlock.
+const strategyRetry = "retry"
+
+// strategyOrdered takes the lower numbered stick first, waiting for each.
+// Since the sticks are taken in one global order, nobody can wait in a cycle.
+const strategyOrdered = "ordered"
+
+// strategies are the built in strategies, by name.
+var strategies = map[string]strategy{
+	strategyRetry:   grabRetrying,
+	strategyOrdered: grabOrdered,
+	strategyFair:    grabFairly,
+}
+
+var flagStrategy = flag.String("strategy", strategyRetry,
+	"how philosophers get their sticks: "+strings.Join(strategyNames(), ", ")+
+		", or a Go plugin .so file exporting Grab")
+
+// grab is how philosophers get their sticks, as set from -strategy by
+// chooseStrategy; a -scenario may give some of them another.
+var grab strategy = grabRetrying
+
+func strategyNames() []string {
+	var names []string
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// chooseStrategy sets grab to a built in strategy, or one loaded from a plugin.
+func chooseStrategy(name string) error {
+	s, err := lookupStrategy(name)
+	if err != nil {
+		return err
+	}
+	grab, waitBound = s, boundOf(name)
+	return nil
+}
+
+// lookupStrategy finds a built in strategy, or loads one from a plugin.
+func lookupStrategy(name string) (strategy, error) {
+	if s, ok := strategies[name]; ok {
+		return s, nil
+	}
+	if !strings.HasSuffix(name, ".so") {
+		return nil, fmt.Errorf("unknown strategy %q; want one of %s, or a plugin .so file",
+			name, strings.Join(strategyNames(), ", "))
+	}
+	return loadStrategy(name)
+}
+
+// loadStrategy loads a strategy from a Go plugin, built with
+// "go build -buildmode=plugin" by the same Go version as this program.
+// The plugin's main package must export a function
+//
+//	func Grab(leftStick, rightStick int,
+//		takeEither func() int, take func(side int, wait bool) bool, put func(side int), waited func())
+//
+// with sides 0 for left and 1 for right, behaving as a strategy.
+func loadStrategy(name string) (strategy, error) {
+	p, err := plugin.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup("Grab")
+	if err != nil {
+		return nil, err
+	}
+	f, ok := sym.(func(int, int, func() int, func(int, bool) bool, func(int), func()))
+	if !ok {
+		return nil, fmt.Errorf("%s: Grab is a %T, not a strategy", name, sym)
+	}
+	return f, nil
+}
+
+func grabRetrying(_, _ int,
+	takeEither func() int, take func(side int, wait bool) bool, put func(side int), waited func()) {
+	for {
+		first := takeEither()
+		if take(1-first, false) {
+			return
+		}
+		put(first)
+		waited()
+	}
+}
+
+func grabOrdered(leftStick, rightStick int,
+	_ func() int, take func(side int, wait bool) bool, _ func(side int), _ func()) {
+	first := sideLeft
+	if rightStick < leftStick {
+		first = sideRight
+	}
+	take(first, true)
+	take(1-first, true)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// The teach command seats a small table by default, so every step can be
+// followed; a -scenario can seat another.
+const (
+	teachPhilosophers = 5
+	teachServings     = 10
+)
+
+// teacher is a spectator for learning how the dinner works. It shows one
+// event at a time, says what it means for the sticks around the table,
+// and waits for a key before taking the next. Events aren't buffered
+// while it watches, so each philosopher is held at its next event, and
+// nobody gets more than a step ahead of what's been shown.
+type teacher struct {
+	keys <-chan byte
+	view *tableView
+	step int
+	// running is set once the rest of the dinner is to be shown without stopping.
+	running bool
+	// cycle is the circular wait last pointed out, so it's said once.
+	cycle string
+}
+
+// newTeacher takes keys as they're pressed, or, if the terminal can't
+// deliver them that way, reads standard input a line at a time, each line
+// counting as one key: its first, or Enter for an empty line.
+func newTeacher(numPhilosophers int, keys <-chan byte) *teacher {
+	if keys == nil {
+		lines := make(chan byte)
+		go func() {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				key := byte('\n')
+				if line := scanner.Bytes(); len(line) > 0 {
+					key = line[0]
+				}
+				lines <- key
+			}
+			close(lines)
+		}()
+		keys = lines
+	}
+	return &teacher{keys: keys, view: newTableView(numPhilosophers)}
+}
+
+func (t *teacher) ownsTerminal() bool { return true }
+
+// focus does nothing, since every step is shown.
+func (t *teacher) focus(int) {}
+
+func (t *teacher) watch(events <-chan event) {
+	fmt.Println(tr(msgTeachKeys))
+	for e := range events {
+		t.view.apply(e)
+		t.step++
+		fmt.Printf("%4d  p%d %s\n", t.step, e.id, e.msg)
+		for _, line := range t.explain(e) {
+			fmt.Println("      " + line)
+		}
+		t.next()
+	}
+}
+
+// next waits for a key, unless the dinner is running on.
+func (t *teacher) next() {
+	if t.running {
+		return
+	}
+	k, ok := <-t.keys
+	if !ok || k == 'r' {
+		t.running = true
+	}
+}
+
+// sticksOf returns the ids of a philosopher's left and right sticks.
+// Stick i lies between philosophers i and i+1.
+func (t *teacher) sticksOf(id int) (left, right int) {
+	n := len(t.view.owner)
+	return (n + id - 1) % n, id
+}
+
+// sharers returns the two philosophers who can use a stick.
+func (t *teacher) sharers(stick int) (int, int) {
+	return stick, (stick + 1) % len(t.view.owner)
+}
+
+// explain says why an event, just applied to the view, is safe or risky.
+func (t *teacher) explain(e event) []string {
+	v := t.view
+	var lines []string
+	switch e.act {
+	case actTakeLeft, actTakeRight:
+		left, right := t.sticksOf(e.id)
+		other := right
+		if e.stick == right {
+			other = left
+		}
+		switch holder := v.owner[other]; {
+		case v.holding[e.id] == 2:
+			lines = append(lines, tr(msgTeachBoth, e.id, left, right))
+		case holder < 0:
+			lines = append(lines, tr(msgTeachOtherFree, other, e.id))
+		default:
+			lines = append(lines, tr(msgTeachOtherHeld, e.id, e.stick, other, holder))
+		}
+	case actRelease:
+		a, b := t.sharers(e.stick)
+		lines = append(lines, tr(msgTeachReleased, e.stick, a, b))
+	case actWait:
+		lines = append(lines, tr(msgTeachWait, e.id, e.tries))
+	case actEat:
+		lines = append(lines, tr(msgTeachEat, e.id, v.eaten[e.id], v.totalEaten))
+	case actThink:
+		lines = append(lines, tr(msgTeachThink, e.id))
+	case actDoneThinking:
+		lines = append(lines, tr(msgTeachHungry, e.id))
+	case actLeave:
+		lines = append(lines, tr(msgTeachLeave, e.id))
+	}
+	cycle := t.circularWait()
+	if cycle != "" && cycle != t.cycle {
+		lines = append(lines, tr(msgTeachCycle, cycle))
+	}
+	t.cycle = cycle
+	return lines
+}
+
+// wantsFrom returns who holds the stick a philosopher holding just one
+// wants, or -1 if it holds none or both, or the stick it wants is free.
+func (t *teacher) wantsFrom(id int) int {
+	if t.view.holding[id] != 1 {
+		return -1
+	}
+	left, right := t.sticksOf(id)
+	want := left
+	if t.view.owner[left] == id {
+		want = right
+	}
+	return t.view.owner[want]
+}
+
+// circularWait describes a cycle of philosophers, each holding one stick
+// and wanting one held by the next, as "p1 → p2 → p1"; or returns "".
+func (t *teacher) circularWait() string {
+	n := len(t.view.owner)
+	for start := 0; start < n; start++ {
+		path := []string{fmt.Sprintf("p%d", start)}
+		for id, steps := t.wantsFrom(start), 0; id >= 0 && steps < n; id, steps = t.wantsFrom(id), steps+1 {
+			path = append(path, fmt.Sprintf("p%d", id))
+			if id == start {
+				return strings.Join(path, " → ")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	flagFormat = flag.String("format", "",
+		"text/template for event lines, with fields .Time .Philosopher .Action .Stick .Tries .Message")
+	flagFormatFile = flag.String("formatFile", "", "file holding a -format template")
+)
+
+// actionNames are the values of .Action in event templates.
+var actionNames = map[action]string{
+	actTakeLeft:     "takeLeft",
+	actTakeRight:    "takeRight",
+	actRelease:      "release",
+	actWait:         "wait",
+	actEat:          "eat",
+	actThink:        "think",
+	actDoneThinking: "doneThinking",
+	actLeave:        "leave",
+}
+
+func (a action) String() string {
+	return actionNames[a]
+}
+
+// eventFields is what an event template sees, and what an event log records.
+type eventFields struct {
+	Time        time.Time
+	Philosopher int
+	Action      string
+	// Stick is -1 if no stick is involved.
+	Stick int
+	Tries int
+	// Message is the event as it would otherwise be printed, in the chosen language.
+	Message string
+}
+
+// parseEventTemplate returns the template given by -format or -formatFile,
+// or nil if there's neither.
+func parseEventTemplate() (*template.Template, error) {
+	text := *flagFormat
+	if *flagFormatFile != "" {
+		if text != "" {
+			return nil, fmt.Errorf("use only one of -format and -formatFile")
+		}
+		b, err := os.ReadFile(*flagFormatFile)
+		if err != nil {
+			return nil, err
+		}
+		text = strings.TrimRight(string(b), "\n")
+	}
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("event").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// Catch references to fields that don't exist before the dinner starts.
+	if err := tmpl.Execute(io.Discard, eventFields{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// formatEvent renders e with tmpl, reporting a failure in place of the line.
+func formatEvent(tmpl *template.Template, e event) string {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, fieldsOf(e)); err != nil {
+		return err.Error()
+	}
+	return b.String()
+}
+
+// fieldsOf returns the fields of e seen by templates and written to event logs.
+func fieldsOf(e event) eventFields {
+	return eventFields{
+		Time:        e.at,
+		Philosopher: e.id,
+		Action:      e.act.String(),
+		Stick:       e.stick,
+		Tries:       e.tries,
+		Message:     e.msg,
+	}
+}
@@ -0,0 +1,9 @@
+package main
+
+import "syscall"
+
+// ioctl requests for terminal attributes.
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin)
+
+package main
+
+// ttySize can't ask the terminal here; callers fall back to the environment.
+func ttySize() (cols, rows int, ok bool) {
+	return 0, 0, false
+}
+
+// cbreak can't change the terminal mode here.
+func cbreak() (restore func(), ok bool) {
+	return nil, false
+}
@@ -0,0 +1,44 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ttySize asks the terminal on standard output for its size.
+func ttySize() (cols, rows int, ok bool) {
+	var ws struct{ row, col, xpixel, ypixel uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(),
+		uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 || ws.col == 0 || ws.row == 0 {
+		return 0, 0, false
+	}
+	return int(ws.col), int(ws.row), true
+}
+
+func termios(fd uintptr, request uintptr, t *syscall.Termios) bool {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(t)))
+	return errno == 0
+}
+
+// cbreak turns off line buffering and echo on standard input, so keys arrive
+// as they're pressed, and returns a func restoring the previous mode.
+// It fails if standard input isn't a terminal.
+func cbreak() (restore func(), ok bool) {
+	fd := os.Stdin.Fd()
+	var old syscall.Termios
+	if !termios(fd, ioctlGetTermios, &old) {
+		return nil, false
+	}
+	raw := old
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if !termios(fd, ioctlSetTermios, &raw) {
+		return nil, false
+	}
+	return func() { termios(fd, ioctlSetTermios, &old) }, true
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var flagTimeline = flag.Bool("timeline", false,
+	"after the dinner, draw what each philosopher did over time, one lane each")
+
+// Glyphs of a timeline lane. A meal takes no time to speak of, so a lane
+// shows eating wherever a meal fell, and otherwise what took most time.
+const (
+	glyphEating   = '■'
+	glyphThinking = '·'
+	glyphWaiting  = '░'
+	glyphGone     = ' '
+)
+
+// timelineTicks is how many columns apart the time axis is marked.
+const timelineTicks = 10
+
+// mark is a moment a philosopher's lane changes.
+type mark struct {
+	at  time.Time
+	act action
+}
+
+// timeline is a spectator that keeps, per philosopher, when it ate, thought
+// and left, to draw each as a lane on a shared time axis once the dinner is over.
+type timeline struct {
+	spectator
+	lanes      [][]mark
+	start, end time.Time
+}
+
+func newTimeline(sp spectator, numPhilosophers int) *timeline {
+	return &timeline{spectator: sp, lanes: make([][]mark, numPhilosophers)}
+}
+
+func (tl *timeline) watch(events <-chan event) {
+	passOn(tl.spectator, events, tl.add)
+}
+
+// add keeps an event, if it changes what a lane shows.
+func (tl *timeline) add(e event) {
+	// Philosophers race to hand over events, so times can be a little out of order.
+	if tl.start.IsZero() || e.at.Before(tl.start) {
+		tl.start = e.at
+	}
+	if e.at.After(tl.end) {
+		tl.end = e.at
+	}
+	switch e.act {
+	case actEat, actThink, actDoneThinking, actLeave:
+		tl.lanes[e.id] = append(tl.lanes[e.id], mark{at: e.at, act: e.act})
+	}
+}
+
+// draw writes the lanes, cols columns wide, the time axis, and a legend.
+func (tl *timeline) draw(w io.Writer, cols int) {
+	label := len(strconv.Itoa(len(tl.lanes)-1)) + 2
+	// One more nanosecond, so the last event falls in the last column.
+	span := tl.end.Sub(tl.start) + 1
+	fmt.Fprintln(w, tr(msgTimeline, span.Round(time.Millisecond)))
+	for id, marks := range tl.lanes {
+		fmt.Fprintf(w, "%-*s%s\n", label, "p"+strconv.Itoa(id), tl.lane(marks, span, cols))
+	}
+	axis := []rune(strings.Repeat("─", cols))
+	for c := 0; c < cols; c += timelineTicks {
+		axis[c] = '┼'
+	}
+	axis[0], axis[cols-1] = '├', '┤'
+	fmt.Fprintf(w, "%*s%s\n", label, "", string(axis))
+
+	// Label ticks, where there's room, with times to within a column.
+	unit := time.Microsecond
+	for unit*10 <= span/time.Duration(cols) {
+		unit *= 10
+	}
+	labels := []rune(strings.Repeat(" ", cols+timelineTicks))
+	free := 0
+	for c := 0; c < cols; c += timelineTicks {
+		text := []rune((span * time.Duration(c) / time.Duration(cols)).Round(unit).String())
+		if c < free || c+len(text) > len(labels) {
+			continue
+		}
+		copy(labels[c:], text)
+		free = c + len(text) + 1
+	}
+	fmt.Fprintf(w, "%*s%s\n", label, "", strings.TrimRight(string(labels), " "))
+	fmt.Fprintf(w, "%*s%s\n", label, "", tr(msgTimelineLegend,
+		string(glyphEating), string(glyphThinking), string(glyphWaiting)))
+}
+
+// lane draws one philosopher's marks across cols columns of span.
+func (tl *timeline) lane(marks []mark, span time.Duration, cols int) string {
+	var b strings.Builder
+	state, i := stateWaiting, 0
+	for c := 0; c < cols; c++ {
+		from := tl.start.Add(span * time.Duration(c) / time.Duration(cols))
+		to := tl.start.Add(span * time.Duration(c+1) / time.Duration(cols))
+		var spent [stateGone + 1]time.Duration
+		ate := false
+		at := from
+		for ; i < len(marks) && marks[i].at.Before(to); i++ {
+			m := marks[i]
+			if m.at.After(at) {
+				spent[state] += m.at.Sub(at)
+				at = m.at
+			}
+			switch m.act {
+			case actEat:
+				ate = true
+			case actThink:
+				state = stateThinking
+			case actDoneThinking:
+				state = stateWaiting
+			case actLeave:
+				state = stateGone
+			}
+		}
+		spent[state] += to.Sub(at)
+		most := stateWaiting
+		for s := range spent {
+			if spent[s] > spent[most] {
+				most = dinerState(s)
+			}
+		}
+		switch {
+		case ate:
+			b.WriteRune(glyphEating)
+		case most == stateThinking:
+			b.WriteRune(glyphThinking)
+		case most == stateGone:
+			b.WriteRune(glyphGone)
+		default:
+			b.WriteRune(glyphWaiting)
+		}
+	}
+	return strings.TrimRight(b.String(), string(glyphGone))
+}
+
+// timelineWidth is how many columns lanes take: the terminal's width,
+// less room for labels, or a width suiting most terminals.
+func timelineWidth(numPhilosophers int) int {
+	cols, _, ok := ttySize()
+	if !ok {
+		cols = 80
+	}
+	cols -= len(strconv.Itoa(numPhilosophers-1)) + 2
+	if cols < timelineTicks {
+		cols = timelineTicks
+	}
+	return cols
+}
+
+// drawTimeline runs "timeline log", drawing the lanes of a recorded dinner.
+func drawTimeline(args []string) error {
+	if len(args) != 1 {
+		return errors.New("timeline needs an event log")
+	}
+	in, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	events, numPhilosophers, err := readEventLog(in)
+	in.Close()
+	if err != nil {
+		return fmt.Errorf("reading event log %s: %v", args[0], err)
+	}
+	if numPhilosophers < 2 || len(events) == 0 {
+		return fmt.Errorf("event log %s has no dinner in it", args[0])
+	}
+	tl := newTimeline(nil, numPhilosophers)
+	for _, e := range events {
+		tl.add(e)
+	}
+	tl.draw(os.Stdout, timelineWidth(numPhilosophers))
+	return nil
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ANSI escape sequences used to drive the terminal.
+const (
+	escAltScreenOn  = "\x1b[?1049h"
+	escAltScreenOff = "\x1b[?1049l"
+	escHideCursor   = "\x1b[?25l"
+	escShowCursor   = "\x1b[?25h"
+	escHome         = "\x1b[H"
+	escReset        = "\x1b[0m"
+)
+
+// Foreground colors, as ANSI SGR codes; colorNone leaves the default.
+const (
+	colorNone   = 0
+	colorRed    = 31
+	colorGreen  = 32
+	colorYellow = 33
+	colorBlue   = 34
+	colorGray   = 90
+)
+
+const (
+	// tuiLogLines is how many recent events the dashboard remembers.
+	tuiLogLines = 200
+	// The dashboard is drawn as if the terminal were at least this big.
+	tuiMinCols = 40
+	tuiMinRows = 12
+)
+
+func stateColor(s dinerState) int {
+	switch s {
+	case stateEating:
+		return colorGreen
+	case stateThinking:
+		return colorBlue
+	case stateGone:
+		return colorGray
+	}
+	return colorYellow
+}
+
+// terminalSize returns the size of the terminal, trying the terminal itself,
+// then the COLUMNS and LINES environment variables, then assuming 80x24.
+func terminalSize() (cols, rows int) {
+	if cols, rows, ok := ttySize(); ok {
+		return cols, rows
+	}
+	cols, rows = 80, 24
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		cols = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		rows = n
+	}
+	return cols, rows
+}
+
+type cell struct {
+	r       rune
+	color   int
+	reverse bool
+}
+
+// screen is a grid of cells, written to the terminal in one go to avoid flicker.
+type screen struct {
+	cols, rows int
+	cells      [][]cell
+}
+
+func newScreen(cols, rows int) *screen {
+	s := &screen{cols: cols, rows: rows, cells: make([][]cell, rows)}
+	for r := range s.cells {
+		s.cells[r] = make([]cell, cols)
+		for c := range s.cells[r] {
+			s.cells[r][c].r = ' '
+		}
+	}
+	return s
+}
+
+// put sets one cell, ignoring positions off the screen.
+func (s *screen) put(col, row int, c cell) {
+	if col < 0 || col >= s.cols || row < 0 || row >= s.rows {
+		return
+	}
+	s.cells[row][col] = c
+}
+
+// text writes str starting at col, clipped to the screen.
+func (s *screen) text(col, row int, str string, color int) {
+	for _, r := range str {
+		s.put(col, row, cell{r: r, color: color})
+		col++
+	}
+}
+
+func (s *screen) String() string {
+	var b strings.Builder
+	b.WriteString(escHome)
+	for r, line := range s.cells {
+		last := cell{color: -1}
+		for _, c := range line {
+			if c.color != last.color || c.reverse != last.reverse {
+				b.WriteString(escReset)
+				if c.color != colorNone {
+					fmt.Fprintf(&b, "\x1b[%dm", c.color)
+				}
+				if c.reverse {
+					b.WriteString("\x1b[7m")
+				}
+			}
+			b.WriteRune(c.r)
+			last = c
+		}
+		b.WriteString(escReset)
+		// No newline after the last row, lest the terminal scroll.
+		if r < len(s.cells)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	return b.String()
+}
+
+// tui is a spectator drawing a full-screen dashboard: the ring of philosophers
+// colored by state, who holds which stick, the rice left, live stats, and a
+// pane of recent events.
+type tui struct {
+	keyboard
+	view     *tableView
+	chef     *chef
+	interval time.Duration
+	started  time.Time
+	focusCh  chan int
+	done     chan struct{}
+	// log holds recent events, oldest first.
+	log []event
+	// For the event rate shown in the stats.
+	lastFrame       time.Time
+	lastFrameEvents int
+	eventRate       float64
+}
+
+func newTUI(numPhilosophers int, c *chef, interval time.Duration, kb keyboard) *tui {
+	return &tui{
+		keyboard: kb,
+		view:     newTableView(numPhilosophers),
+		chef:     c,
+		interval: interval,
+		focusCh:  make(chan int),
+		done:     make(chan struct{}),
+	}
+}
+
+func (t *tui) ownsTerminal() bool { return true }
+
+func (t *tui) focus(id int) {
+	select {
+	case t.focusCh <- id:
+	case <-t.done:
+	}
+}
+
+func (t *tui) watch(events <-chan event) {
+	defer close(t.done)
+	fmt.Print(escAltScreenOn + escHideCursor)
+	defer fmt.Print(escShowCursor + escAltScreenOff)
+	t.started = time.Now()
+	t.lastFrame = t.started
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case t.focused = <-t.focusCh:
+		case k := <-t.keys:
+			t.press(k, t.view)
+			t.draw()
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			t.view.apply(e)
+			t.remember(e)
+		case <-ticker.C:
+			t.draw()
+		}
+	}
+}
+
+func (t *tui) remember(e event) {
+	if !t.shows(e) {
+		return
+	}
+	if len(t.log) == tuiLogLines {
+		t.log = t.log[1:]
+	}
+	t.log = append(t.log, e)
+}
+
+func (t *tui) draw() {
+	now := time.Now()
+	if secs := now.Sub(t.lastFrame).Seconds(); secs > 0 {
+		t.eventRate = float64(t.view.totalEvents-t.lastFrameEvents) / secs
+	}
+	t.lastFrame, t.lastFrameEvents = now, t.view.totalEvents
+
+	cols, rows := terminalSize()
+	if cols < tuiMinCols {
+		cols = tuiMinCols
+	}
+	if rows < tuiMinRows {
+		rows = tuiMinRows
+	}
+	s := newScreen(cols, rows)
+	title := tr(msgTUITitle, len(t.view.states))
+	s.text(0, 0, title, colorNone)
+	// Translations aren't all ASCII, so widths are counted in runes.
+	keyHelp := tr(msgKeyHelp)
+	if t.keys != nil && utf8.RuneCountInString(title)+2+utf8.RuneCountInString(keyHelp) <= cols {
+		s.text(cols-utf8.RuneCountInString(keyHelp), 0, keyHelp, colorGray)
+	}
+	ringCols := cols * 3 / 5
+	t.drawRing(s, 0, 1, ringCols, rows-2)
+	row := t.drawStats(s, ringCols+1, 1)
+	t.drawLog(s, ringCols+1, row+1, rows-1)
+	s.text(0, rows-1, tr(msgTUILegend), colorGray)
+	fmt.Print(s)
+}
+
+// drawRing lays philosophers and sticks alternately around an ellipse,
+// starting at the top and going clockwise. On a small terminal with many
+// philosophers, philosophers are drawn over sticks.
+func (t *tui) drawRing(s *screen, x0, y0, w, h int) {
+	n := len(t.view.states)
+	cx, cy := x0+w/2, y0+h/2
+	rx, ry := float64(w/2-1), float64(h/2-1)
+	at := func(k int) (int, int) {
+		angle := 2*math.Pi*float64(k)/float64(2*n) - math.Pi/2
+		return cx + int(math.Round(rx*math.Cos(angle))), cy + int(math.Round(ry*math.Sin(angle)))
+	}
+	for i, owner := range t.view.owner {
+		c := cell{r: '|', color: colorNone}
+		switch owner {
+		case i:
+			c = cell{r: '<', color: stateColor(t.view.states[owner])}
+		case (i + 1) % n:
+			c = cell{r: '>', color: stateColor(t.view.states[owner])}
+		}
+		col, row := at(2*i + 1)
+		s.put(col, row, c)
+	}
+	for i, state := range t.view.states {
+		col, row := at(2 * i)
+		color := stateColor(state)
+		if state == stateWaiting && t.view.eaten[i] == 0 && t.view.waits[i] > 0 {
+			color = colorRed
+		}
+		s.put(col, row, cell{r: state.glyph(), color: color, reverse: i == t.focused})
+	}
+	rice := tr(msgTUIRice, t.chef.servingsLeft())
+	s.text(cx-utf8.RuneCountInString(rice)/2, cy, rice, colorNone)
+}
+
+// drawStats returns the row after the last one drawn.
+func (t *tui) drawStats(s *screen, x0, y0 int) int {
+	v := t.view
+	starving := 0
+	for id, n := range v.eaten {
+		if n == 0 && v.states[id] != stateGone {
+			starving++
+		}
+	}
+	lines := []string{
+		tr(msgTUIElapsed, time.Since(t.started).Round(time.Millisecond)),
+		tr(msgTUIServings, v.totalEaten),
+		tr(msgTUIEvents, v.totalEvents, t.eventRate),
+		tr(msgTUIEating, v.count(stateEating)),
+		tr(msgTUIThinking, v.count(stateThinking)),
+		tr(msgTUIWaiting, v.count(stateWaiting)),
+		tr(msgTUIGone, v.count(stateGone)),
+		tr(msgTUIUnfed, starving),
+	}
+	if id := v.hungriest(); id >= 0 {
+		lines = append(lines, tr(msgTUIHungriest, id, v.waits[id], v.eaten[id]))
+	}
+	if id := t.focused; id >= 0 {
+		lines = append(lines, tr(msgTUIWatching, id, v.waits[id], v.eaten[id]))
+	}
+	for i, line := range lines {
+		s.text(x0, y0+i, line, colorNone)
+	}
+	return y0 + len(lines)
+}
+
+// drawLog shows as many recent events as fit between rows y0 and y1, newest last.
+func (t *tui) drawLog(s *screen, x0, y0, y1 int) {
+	s.text(x0, y0, tr(msgTUIRecent), colorGray)
+	fit := y1 - y0 - 1
+	if fit <= 0 {
+		return
+	}
+	log := t.log
+	if len(log) > fit {
+		log = log[len(log)-fit:]
+	}
+	for i, e := range log {
+		s.text(x0, y0+1+i, fmt.Sprintf("p%d %s", e.id, e.msg), colorNone)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+// dinerState is what a philosopher appears to be doing, judging by its events.
+type dinerState int
+
+const (
+	stateWaiting dinerState = iota
+	stateEating
+	stateThinking
+	stateGone
+)
+
+// glyph is the letter used for a state on screen.
+func (s dinerState) glyph() rune {
+	switch s {
+	case stateEating:
+		return 'E'
+	case stateThinking:
+		return 'T'
+	case stateGone:
+		return 'x'
+	}
+	return 'W'
+}
+
+// tableView is a picture of the table kept current by applying events.
+// It belongs to a single spectator goroutine.
+type tableView struct {
+	states  []dinerState
+	holding []int
+	eaten   []int
+	waits   []int
+	// owner holds, per stick, the philosopher holding it, or -1 if it's in its tray.
+	owner       []int
+	totalEvents int
+	totalEaten  int
+}
+
+func newTableView(numPhilosophers int) *tableView {
+	v := &tableView{
+		states:  make([]dinerState, numPhilosophers),
+		holding: make([]int, numPhilosophers),
+		eaten:   make([]int, numPhilosophers),
+		waits:   make([]int, numPhilosophers),
+		owner:   make([]int, numPhilosophers),
+	}
+	for i := range v.owner {
+		v.owner[i] = -1
+	}
+	return v
+}
+
+func (v *tableView) apply(e event) {
+	v.totalEvents++
+	switch e.act {
+	case actTakeLeft, actTakeRight:
+		v.owner[e.stick] = e.id
+		v.holding[e.id]++
+		if v.holding[e.id] == 2 {
+			v.states[e.id] = stateEating
+		}
+	case actRelease:
+		v.owner[e.stick] = -1
+		v.holding[e.id]--
+	case actWait:
+		v.waits[e.id]++
+	case actEat:
+		v.eaten[e.id]++
+		v.totalEaten++
+	case actThink:
+		v.states[e.id] = stateThinking
+	case actDoneThinking:
+		v.states[e.id] = stateWaiting
+	case actLeave:
+		v.states[e.id] = stateGone
+	}
+}
+
+// count returns how many philosophers are in the given state.
+func (v *tableView) count(s dinerState) int {
+	n := 0
+	for _, state := range v.states {
+		if state == s {
+			n++
+		}
+	}
+	return n
+}
+
+// hungriest returns the philosopher still at the table who has waited most
+// for sticks relative to what they've eaten, or -1 if everyone has left.
+func (v *tableView) hungriest() int {
+	best, bestScore := -1, -1
+	for id, state := range v.states {
+		if state == stateGone {
+			continue
+		}
+		score := v.waits[id] / (v.eaten[id] + 1)
+		if score > bestScore {
+			best, bestScore = id, score
+		}
+	}
+	return best
+}
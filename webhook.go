@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var flagWebhook = flag.String("webhook", "",
+	"URL to post a JSON report to on the first starvation, a watchdog alarm and the end of the dinner")
+
+// webhookTimeout bounds how long a post may take, so a dead endpoint
+// can't keep a finished dinner from exiting.
+const webhookTimeout = 10 * time.Second
+
+// webhookPayload is what's posted to a webhook.
+type webhookPayload struct {
+	// Alarm is "starved", "stalled", "done" or "checkpointed".
+	Alarm   string     `json:"alarm"`
+	Message string     `json:"message"`
+	Time    time.Time  `json:"time"`
+	Report  jsonReport `json:"report"`
+}
+
+// postWebhook posts an alarm, and the report that goes with it, to url.
+func postWebhook(url, alarm, msg string, report jsonReport) error {
+	body, err := json.Marshal(webhookPayload{
+		Alarm:   alarm,
+		Message: msg,
+		Time:    time.Now(),
+		Report:  report,
+	})
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook: %s answered %s", url, resp.Status)
+	}
+	return nil
+}